@@ -14,15 +14,21 @@ limitations under the License.
 package analyze
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/ai/interactive"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
@@ -41,6 +47,21 @@ var (
 	customAnalysis  bool
 	customHeaders   []string
 	withStats       bool
+	fromSnapshot    string
+	watch           bool
+	watchInterval   time.Duration
+	baseline        string
+	failOn          string
+	contexts        []string
+	allContexts     bool
+	suppressions    string
+	maxAICalls      int
+	maxTokensTotal  int
+	maxTokensPerDay int
+	remediate       bool
+	apply           bool
+	batch           bool
+	recordAI        string
 )
 
 // AnalyzeCmd represents the problems command
@@ -51,6 +72,47 @@ var AnalyzeCmd = &cobra.Command{
 	Long: `This command will find problems within your Kubernetes cluster and
 	provide you with a list of issues that need to be resolved`,
 	Run: func(cmd *cobra.Command, args []string) {
+		var failOnSeverity common.Severity
+		if failOn != "" {
+			var err error
+			failOnSeverity, err = common.ParseSeverity(failOn)
+			if err != nil {
+				color.Red("Error: --fail-on: %v", err)
+				os.Exit(1)
+			}
+			if watch {
+				color.Red("Error: --fail-on is not supported in watch mode")
+				os.Exit(1)
+			}
+		}
+
+		if remediate && (!explain || watch || baseline != "" || fromSnapshot != "" || anonymize) {
+			color.Red("Error: --remediate requires --explain and is not supported in watch mode, with --baseline, --from-snapshot or --anonymize")
+			os.Exit(1)
+		}
+		if apply && (!remediate || output != "text") {
+			color.Red("Error: --apply requires --remediate and the text output")
+			os.Exit(1)
+		}
+
+		if allContexts {
+			var err error
+			contexts, err = kubernetes.ListContexts(viper.GetString("kubeconfig"))
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+		}
+		if len(contexts) > 0 && (watch || fromSnapshot != "") {
+			color.Red("Error: --contexts and --all-contexts are not supported in watch mode or with --from-snapshot")
+			os.Exit(1)
+		}
+
+		// Cached completions would not be recorded.
+		if recordAI != "" {
+			nocache = true
+		}
+
 		// Create analysis configuration first.
 		config, err := analysis.NewAnalysis(
 			backend,
@@ -65,6 +127,9 @@ var AnalyzeCmd = &cobra.Command{
 			interactiveMode,
 			customHeaders,
 			withStats,
+			fromSnapshot,
+			contexts,
+			suppressions,
 		)
 
 		if err != nil {
@@ -73,17 +138,85 @@ var AnalyzeCmd = &cobra.Command{
 		}
 		defer config.Close()
 
+		var recorder *ai.Recorder
+		if recordAI != "" {
+			if !explain {
+				color.Red("Error: --record-ai requires --explain")
+				os.Exit(1)
+			}
+			recorder, err = ai.NewRecorder(config.AIClient, recordAI)
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			config.AIClient = recorder
+		}
+
+		if maxTokensPerDay == 0 {
+			maxTokensPerDay = viper.GetInt("ai.maxtokensperday")
+		}
+		if err := config.SetBudget(analysis.Budget{
+			MaxCalls:        maxAICalls,
+			MaxTokens:       maxTokensTotal,
+			MaxTokensPerDay: maxTokensPerDay,
+		}); err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+
+		config.Batch = batch
+
+		if watch {
+			runWatch(config)
+			return
+		}
+
+		var baselineOutput *analysis.JsonOutput
+		if baseline != "" {
+			if interactiveMode {
+				color.Red("Error: interactive mode is not supported with --baseline")
+				os.Exit(1)
+			}
+			baselineOutput, err = analysis.LoadJsonOutput(baseline)
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+		}
+
 		if customAnalysis {
 			config.RunCustomAnalysis()
 		}
 		config.RunAnalysis()
 
-		if explain {
+		// The text output streams the explanations when the backend supports it.
+		streamed := explain && output == "text" && baselineOutput == nil && !remediate && !batch && ai.CanStream(config.AIClient)
+		if streamed {
+			if err := config.StreamAIResults(os.Stdout, anonymize); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+		} else if explain {
 			if err := config.GetAIResults(output, anonymize); err != nil {
 				color.Red("Error: %v", err)
 				os.Exit(1)
 			}
 		}
+		if remediate {
+			config.Remediate()
+		}
+		if recorder != nil {
+			if err := recorder.Save(); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+		}
+		if baselineOutput != nil {
+			diff := analysis.DiffResults(baselineOutput.Results, config.Results)
+			printDiff(&diff, output, failOnSeverity)
+			return
+		}
+
 		// print results
 		output_data, err := config.PrintOutput(output)
 		if err != nil {
@@ -96,7 +229,19 @@ var AnalyzeCmd = &cobra.Command{
 			fmt.Println(string(statsData))
 		}
 
-		fmt.Println(string(output_data))
+		if !streamed {
+			fmt.Println(string(output_data))
+		}
+
+		if apply {
+			applyRemediations(config)
+		}
+
+		// The exit code is set by --fail-on, after the interactive session.
+		exitCode := 0
+		if failOnSeverity != "" && config.CountAtLeast(failOnSeverity) > 0 {
+			exitCode = 1
+		}
 
 		if interactiveMode && explain {
 			if output == "json" {
@@ -104,7 +249,7 @@ var AnalyzeCmd = &cobra.Command{
 			}
 			sigs := make(chan os.Signal, 1)
 			signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-			interactiveClient := interactive.NewInteractionRunner(config, output_data)
+			interactiveClient := interactive.NewInteractionRunner(config, output_data, anonymize)
 
 			go interactiveClient.StartInteraction()
 			for {
@@ -112,22 +257,62 @@ var AnalyzeCmd = &cobra.Command{
 				case res := <-sigs:
 					switch res {
 					default:
-						os.Exit(0)
+						os.Exit(exitCode)
 					}
 				case res := <-interactiveClient.State:
 					switch res {
 					case interactive.E_EXITED:
-						os.Exit(0)
+						os.Exit(exitCode)
 					}
 				}
 			}
 		}
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
 	},
 }
 
+func runWatch(config *analysis.Analysis) {
+	if interactiveMode {
+		color.Red("Error: interactive mode is not supported in watch mode")
+		os.Exit(1)
+	}
+	if err := analysis.CheckWatchOutput(output); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	if customAnalysis {
+		color.Yellow("Warning: custom analyzers are not supported in watch mode and will be skipped")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	config.Context = ctx
+
+	err := config.Watch(ctx, watchInterval, anonymize, func(event analysis.WatchEvent) {
+		output_data, err := analysis.PrintWatchEvent(output, event)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(output_data))
+	})
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+}
+
 func init() {
+	AnalyzeCmd.AddCommand(diffCmd)
+
 	// namespace flag
-	AnalyzeCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to analyze")
+	AnalyzeCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespaces to analyze, comma separated (e.g. a,b,c)")
+	// contexts flags
+	AnalyzeCmd.Flags().StringSliceVar(&contexts, "contexts", []string{}, "Kubernetes contexts to analyze concurrently, comma separated (e.g. ctx1,ctx2). Results carry the context they were found in as their cluster")
+	AnalyzeCmd.Flags().BoolVar(&allContexts, "all-contexts", false, "Analyze every context of the kubeconfig concurrently")
+	AnalyzeCmd.MarkFlagsMutuallyExclusive("contexts", "all-contexts")
 	// no cache flag
 	AnalyzeCmd.Flags().BoolVarP(&nocache, "no-cache", "c", false, "Do not use cached data")
 	// anonymize flag
@@ -139,11 +324,11 @@ func init() {
 	// add flag for backend
 	AnalyzeCmd.Flags().StringVarP(&backend, "backend", "b", "", "Backend AI provider")
 	// output as json
-	AnalyzeCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format (text, json)")
+	AnalyzeCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format (text, json, sarif, junit, markdown)")
 	// add language options for output
 	AnalyzeCmd.Flags().StringVarP(&language, "language", "l", "english", "Languages to use for AI (e.g. 'English', 'Spanish', 'French', 'German', 'Italian', 'Portuguese', 'Dutch', 'Russian', 'Chinese', 'Japanese', 'Korean')")
 	// add max concurrency
-	AnalyzeCmd.Flags().IntVarP(&maxConcurrency, "max-concurrency", "m", 10, "Maximum number of concurrent requests to the Kubernetes API server")
+	AnalyzeCmd.Flags().IntVarP(&maxConcurrency, "max-concurrency", "m", 10, "Maximum number of concurrent requests to the Kubernetes API server and to the AI provider")
 	// kubernetes doc flag
 	AnalyzeCmd.Flags().BoolVarP(&withDoc, "with-doc", "d", false, "Give me the official documentation of the involved field")
 	// interactive mode flag
@@ -156,4 +341,26 @@ func init() {
 	AnalyzeCmd.Flags().StringVarP(&labelSelector, "selector", "L", "", "Label selector (label query) to filter on, supports '=', '==', and '!='. (e.g. -L key1=value1,key2=value2). Matching objects must satisfy all of the specified label constraints.")
 	// print stats
 	AnalyzeCmd.Flags().BoolVarP(&withStats, "with-stat", "s", false, "Print analysis stats. This option disables errors display.")
+	// snapshot flag
+	AnalyzeCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "Analyze a cluster snapshot instead of a live cluster. Accepts a directory, a manifest file or a .tar/.tar.gz archive of YAML/JSON manifests (e.g. the output of `kubectl get -A -o yaml`)")
+	// watch flags
+	AnalyzeCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Keep watching the cluster and print new, still failing and resolved problems as objects change")
+	AnalyzeCmd.Flags().DurationVar(&watchInterval, "watch-interval", 10*time.Second, "How often changes are re-evaluated in watch mode")
+	// baseline flag
+	AnalyzeCmd.Flags().StringVar(&baseline, "baseline", "", "Compare the results with a previous `k8sgpt analyze -o json` run and only report the difference. Exits with code 1 when new problems are found")
+	// AI budget flags
+	AnalyzeCmd.Flags().IntVar(&maxAICalls, "max-ai-calls", 0, "Maximum number of calls to the AI provider, the most severe results are explained first (default unlimited)")
+	AnalyzeCmd.Flags().IntVar(&maxTokensTotal, "max-tokens-total", 0, "Maximum number of tokens used to explain the results (default unlimited)")
+	AnalyzeCmd.Flags().IntVar(&maxTokensPerDay, "max-tokens-per-day", 0, "Maximum number of tokens used per day to explain results, across runs (default ai.maxtokensperday from the config, or unlimited)")
+	// suppressions flag
+	AnalyzeCmd.Flags().StringVar(&suppressions, "suppressions", "", "Path of a YAML file of accepted problems to drop from the results (default suppressions_file from the config, or .k8sgptignore if it exists)")
+	// record AI flag
+	AnalyzeCmd.Flags().StringVar(&recordAI, "record-ai", "", "Record the prompts and completions of the AI provider to this cassette file, to be replayed by the replay backend")
+	// batch flag
+	AnalyzeCmd.Flags().BoolVar(&batch, "batch", false, "Explain several problems per prompt, sized to the context window of the AI provider (set with auth add --context-window)")
+	// remediation flags
+	AnalyzeCmd.Flags().BoolVar(&remediate, "remediate", false, "Ask the AI provider for a patch fixing each explained problem, validated with a server-side dry-run and shown as a diff")
+	AnalyzeCmd.Flags().BoolVar(&apply, "apply", false, "Apply the validated patches of --remediate, after confirming each of them")
+	// fail on flag
+	AnalyzeCmd.Flags().StringVar(&failOn, "fail-on", "", "Exit with code 1 when a problem of at least this severity is found (critical, high, medium, low, info)")
 }
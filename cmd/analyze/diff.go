@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyze
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/cobra"
+)
+
+var diffOutput string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old.json> <new.json>",
+	Short: "Compare the results of two analysis runs",
+	Long: `The diff command compares two "k8sgpt analyze -o json" results and reports the problems
+that were added, removed or unchanged. Problems are matched by their fingerprint, which stays the
+same when pods are recreated. It exits with code 1 when the newer run found new problems.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		oldOutput, err := analysis.LoadJsonOutput(args[0])
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		newOutput, err := analysis.LoadJsonOutput(args[1])
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+
+		diff := analysis.DiffResults(oldOutput.Results, newOutput.Results)
+		printDiff(&diff, diffOutput, "")
+	},
+}
+
+// printDiff prints the diff and exits with code 1 when it has regressions, or
+// only new problems of at least failOnSeverity if set.
+func printDiff(diff *analysis.DiffOutput, format string, failOnSeverity common.Severity) {
+	output_data, err := diff.PrintOutput(format)
+	if err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(output_data))
+
+	failed := diff.HasRegressions()
+	if failOnSeverity != "" {
+		failed = diff.CountAtLeast(failOnSeverity) > 0
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	// output as json
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "text", "Output format (text, json)")
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyze
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+)
+
+// applyRemediations applies the validated patches of the results, after
+// asking for confirmation of each of them.
+func applyRemediations(config *analysis.Analysis) {
+	reader := bufio.NewReader(os.Stdin)
+	for index, result := range config.Results {
+		remediation := result.Remediation
+		if remediation == nil || remediation.Diff == "" || remediation.Error != "" {
+			continue
+		}
+		fmt.Printf("Apply the patch of %s %s to %s? [y/N] ", result.Kind, result.Name, remediation.Object)
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			// No more input, skip the remaining patches.
+			fmt.Println()
+			return
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			if err := config.ApplyRemediation(index); err != nil {
+				color.Red("Error: %v", err)
+				continue
+			}
+			color.Green("%s patched", remediation.Object)
+		default:
+			color.Yellow("%s %s skipped", result.Kind, result.Name)
+		}
+	}
+}
@@ -51,6 +51,9 @@ var addCmd = &cobra.Command{
 		if strings.ToLower(backend) == "ibmwatsonxai" {
 			_ = cmd.MarkFlagRequired("providerId")
 		}
+		if strings.ToLower(backend) == "replay" || strings.ToLower(backend) == "http" {
+			_ = cmd.MarkFlagRequired("baseurl")
+		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 
@@ -114,6 +117,27 @@ var addCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if requestsPerMinute < 0 {
+			color.Red("Error: requests-per-minute must not be negative.")
+			os.Exit(1)
+		}
+		if inputCost < 0 || outputCost < 0 {
+			color.Red("Error: input-cost and output-cost must not be negative.")
+			os.Exit(1)
+		}
+		if contextWindow < 0 {
+			color.Red("Error: context-window must not be negative.")
+			os.Exit(1)
+		}
+
+		if passwordRef != "" {
+			if err := ai.ValidatePasswordRef(passwordRef); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			password = passwordRef
+		}
+
 		if ai.NeedPassword(backend) && password == "" {
 			fmt.Printf("Enter %s Key: ", backend)
 			bytePassword, err := term.ReadPassword(int(syscall.Stdin))
@@ -127,20 +151,24 @@ var addCmd = &cobra.Command{
 
 		// create new provider object
 		newProvider := ai.AIProvider{
-			Name:           backend,
-			Model:          model,
-			Password:       password,
-			BaseURL:        baseURL,
-			EndpointName:   endpointName,
-			Engine:         engine,
-			Temperature:    temperature,
-			ProviderRegion: providerRegion,
-			ProviderId:     providerId,
-			CompartmentId:  compartmentId,
-			TopP:           topP,
-			TopK:           topK,
-			MaxTokens:      maxTokens,
-			OrganizationId: organizationId,
+			Name:              backend,
+			Model:             model,
+			Password:          password,
+			BaseURL:           baseURL,
+			EndpointName:      endpointName,
+			Engine:            engine,
+			Temperature:       temperature,
+			ProviderRegion:    providerRegion,
+			ProviderId:        providerId,
+			CompartmentId:     compartmentId,
+			TopP:              topP,
+			TopK:              topK,
+			MaxTokens:         maxTokens,
+			OrganizationId:    organizationId,
+			RequestsPerMinute: requestsPerMinute,
+			InputCost:         inputCost,
+			OutputCost:        outputCost,
+			ContextWindow:     contextWindow,
 		}
 
 		if providerIndex == -1 {
@@ -163,6 +191,9 @@ func init() {
 	addCmd.Flags().StringVarP(&model, "model", "m", defaultModel, "Backend AI model")
 	// add flag for password
 	addCmd.Flags().StringVarP(&password, "password", "p", "", "Backend AI password")
+	// add flag for a reference to the password
+	addCmd.Flags().StringVar(&passwordRef, "password-ref", "", "Reference to the backend AI password, resolved when used: env:VAR, file:path, exec:command args or k8s:namespace/secret/key")
+	addCmd.MarkFlagsMutuallyExclusive("password", "password-ref")
 	// add flag for url
 	addCmd.Flags().StringVarP(&baseURL, "baseurl", "u", "", "URL AI provider, (e.g `http://localhost:8080/v1`)")
 	// add flag for endpointName
@@ -185,4 +216,11 @@ func init() {
 	addCmd.Flags().StringVarP(&compartmentId, "compartmentId", "k", "", "Compartment ID for generative AI model (only for oci backend)")
 	// add flag for openai organization
 	addCmd.Flags().StringVarP(&organizationId, "organizationId", "o", "", "OpenAI or AzureOpenAI Organization ID (only for openai and azureopenai backend)")
+	// add flag for requestsPerMinute
+	addCmd.Flags().Float64Var(&requestsPerMinute, "requests-per-minute", 0, "Maximum number of requests per minute to the AI provider, 0 for no limit")
+	// add flags for the prices of the tokens
+	addCmd.Flags().Float64Var(&inputCost, "input-cost", 0, "Price of a million prompt tokens, to report the cost of the explanations")
+	addCmd.Flags().Float64Var(&outputCost, "output-cost", 0, "Price of a million completion tokens, to report the cost of the explanations")
+	// add flag for contextWindow
+	addCmd.Flags().IntVar(&contextWindow, "context-window", 0, "Number of tokens of the model's context window, sizing the batches of analyze --batch (default 8192)")
 }
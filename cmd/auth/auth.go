@@ -19,20 +19,25 @@ import (
 )
 
 var (
-	backend        string
-	password       string
-	baseURL        string
-	endpointName   string
-	model          string
-	engine         string
-	temperature    float32
-	providerRegion string
-	providerId     string
-	compartmentId  string
-	topP           float32
-	topK           int32
-	maxTokens      int
-	organizationId string
+	backend           string
+	password          string
+	passwordRef       string
+	baseURL           string
+	endpointName      string
+	model             string
+	engine            string
+	temperature       float32
+	providerRegion    string
+	providerId        string
+	compartmentId     string
+	topP              float32
+	topK              int32
+	maxTokens         int
+	organizationId    string
+	requestsPerMinute float64
+	inputCost         float64
+	outputCost        float64
+	contextWindow     int
 )
 
 var configAI ai.AIConfiguration
@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -58,6 +59,14 @@ var updateCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if passwordRef != "" {
+			if err := ai.ValidatePasswordRef(passwordRef); err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			password = passwordRef
+		}
+
 		foundBackend := false
 		for i, provider := range configAI.Providers {
 			if backend == provider.Name {
@@ -109,6 +118,9 @@ func init() {
 	updateCmd.Flags().StringVarP(&model, "model", "m", "", "Update backend AI model")
 	// update flag for password
 	updateCmd.Flags().StringVarP(&password, "password", "p", "", "Update backend AI password")
+	// update flag for a reference to the password
+	updateCmd.Flags().StringVar(&passwordRef, "password-ref", "", "Update the reference to the backend AI password: env:VAR, file:path, exec:command args or k8s:namespace/secret/key")
+	updateCmd.MarkFlagsMutuallyExclusive("password", "password-ref")
 	// update flag for url
 	updateCmd.Flags().StringVarP(&baseURL, "baseurl", "u", "", "Update URL AI provider, (e.g `http://localhost:8080/v1`)")
 	// add flag for temperature
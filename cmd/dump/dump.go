@@ -60,9 +60,12 @@ var DumpCmd = &cobra.Command{
 			// we blank out the custom headers for data protection reasons
 			config.CustomHeaders = make([]http.Header, 0)
 			// blank out the password
-			if len(config.Password) > 4 {
+			switch {
+			case ai.IsPasswordRef(config.Password):
+				// A reference doesn't hold the password, report it as is.
+			case len(config.Password) > 4:
 				config.Password = config.Password[:4] + "***"
-			} else {
+			default:
 				// If the password is shorter than 4 characters
 				config.Password = "***"
 			}
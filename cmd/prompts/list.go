@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prompts
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the prompts",
+	Long:  `The list command displays the built-in prompts and the ones overriding them.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var builtin []string
+		for key := range ai.PromptMap {
+			if key != "raw" && key != "default" {
+				builtin = append(builtin, key)
+			}
+		}
+		sort.Strings(builtin)
+		fmt.Print(color.YellowString("Built-in: \n"))
+		fmt.Printf("> %s\n", color.GreenString("default"))
+		for _, key := range builtin {
+			fmt.Printf("> %s\n", color.GreenString("kind=%s", key))
+		}
+
+		prompts := configuredPrompts()
+		if len(prompts) == 0 {
+			return
+		}
+		fmt.Print(color.YellowString("Configured: \n"))
+		for _, prompt := range prompts {
+			source := "inline"
+			if prompt.File != "" {
+				source = prompt.File
+			}
+			fmt.Printf("> %s (%s)\n", color.BlueString(prompt.Selector()), source)
+		}
+	},
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prompts
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	kind     string
+	analyzer string
+	language string
+	// showLanguage defaults to the language of analyze.
+	showLanguage string
+)
+
+var PromptsCmd = &cobra.Command{
+	Use:     "prompts",
+	Aliases: []string{"prompt"},
+	Short:   "Manage the prompts sent to the AI backend",
+	Long: `The prompts command allows you to override the built-in prompts per Kind, analyzer and language.
+	Prompts are text/templates with the variables kind, name, namespace, parent, language, failures and events.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			_ = cmd.Help()
+			return
+		}
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{showCmd, setCmd, resetCmd} {
+		c.Flags().StringVar(&kind, "kind", "", "Kind of the results the prompt applies to")
+		c.Flags().StringVar(&analyzer, "analyzer", "", "Analyzer of the results the prompt applies to")
+	}
+	showCmd.Flags().StringVar(&showLanguage, "language", "english", "Language the results are explained in")
+	for _, c := range []*cobra.Command{setCmd, resetCmd} {
+		c.Flags().StringVar(&language, "language", "", "Language the prompt applies to")
+	}
+	PromptsCmd.AddCommand(listCmd)
+	PromptsCmd.AddCommand(showCmd)
+	PromptsCmd.AddCommand(setCmd)
+	PromptsCmd.AddCommand(resetCmd)
+}
+
+// selector returns the prompt selected by the flags.
+func selector() ai.PromptConfig {
+	return ai.PromptConfig{Kind: kind, Analyzer: analyzer, Language: language}
+}
+
+// configuredPrompts returns the `prompts` section of the configuration file.
+func configuredPrompts() []ai.PromptConfig {
+	var prompts []ai.PromptConfig
+	if err := viper.UnmarshalKey("prompts", &prompts); err != nil {
+		color.Red("Error: %v", err)
+		os.Exit(1)
+	}
+	return prompts
+}
+
+func writePrompts(prompts []ai.PromptConfig) {
+	viper.Set("prompts", prompts)
+	if err := viper.WriteConfig(); err != nil {
+		color.Red("Error writing config file: %s", err.Error())
+		os.Exit(1)
+	}
+}
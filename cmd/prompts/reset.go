@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prompts
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var all bool
+
+var resetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Restore the built-in prompt",
+	Long:  `The reset command removes the prompt configured for the given selectors, or all of them with --all.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if all {
+			writePrompts(nil)
+			color.Green("All prompts reset")
+			return
+		}
+		prompt := selector()
+		prompts := configuredPrompts()
+		for i, p := range prompts {
+			if p.SameSelector(prompt) {
+				writePrompts(append(prompts[:i], prompts[i+1:]...))
+				color.Green("Prompt %s reset", prompt.Selector())
+				return
+			}
+		}
+		color.Red("Error: no prompt configured for %s", prompt.Selector())
+		os.Exit(1)
+	},
+}
+
+func init() {
+	resetCmd.Flags().BoolVar(&all, "all", false, "Reset all the prompts")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/spf13/cobra"
+)
+
+var (
+	file           string
+	promptTemplate string
+)
+
+var setCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Override the prompt of a Kind, analyzer or language",
+	Long: `The set command configures the prompt used for the results matching all of the given selectors.
+	The template is read from --file at every analysis, or stored inline with --template.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if (file == "") == (promptTemplate == "") {
+			color.Red("Error: exactly one of --file or --template must be set")
+			os.Exit(1)
+		}
+		prompt := selector()
+		if file != "" {
+			path, err := filepath.Abs(file)
+			if err != nil {
+				color.Red("Error: %v", err)
+				os.Exit(1)
+			}
+			prompt.File = path
+		} else {
+			prompt.Template = promptTemplate
+		}
+		source, err := prompt.Source()
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		if _, err := ai.ParsePromptTemplate(prompt.Selector(), source); err != nil {
+			color.Red("Error: invalid prompt template: %v", err)
+			os.Exit(1)
+		}
+
+		prompts := configuredPrompts()
+		replaced := false
+		for i, p := range prompts {
+			if p.SameSelector(prompt) {
+				prompts[i] = prompt
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			prompts = append(prompts, prompt)
+		}
+		writePrompts(prompts)
+		color.Green("Prompt %s set", prompt.Selector())
+	},
+}
+
+func init() {
+	setCmd.Flags().StringVarP(&file, "file", "f", "", "File containing the prompt template")
+	setCmd.Flags().StringVarP(&promptTemplate, "template", "t", "", "Prompt template")
+}
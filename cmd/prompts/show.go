@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prompts
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the prompt used for a Kind, analyzer and language",
+	Long: `The show command displays the prompt used to explain the results of the given Kind and analyzer in the given language.
+	Without an analyzer, the prompts configured for specific analyzers are displayed too.
+	Without a configured prompt, the built-in one is displayed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		prompts, err := ai.NewPrompts(configuredPrompts())
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		if analyzer == "" {
+			for _, tmpl := range prompts.AnalyzerPrompts(kind, showLanguage) {
+				printConfigured(tmpl)
+			}
+		}
+		if tmpl := prompts.Find(kind, analyzer, showLanguage); tmpl != nil {
+			printConfigured(tmpl)
+			return
+		}
+		fmt.Print(color.YellowString("Built-in: \n"))
+		fmt.Println(strings.TrimSpace(ai.BuiltinPrompt(kind)))
+	},
+}
+
+func printConfigured(tmpl *ai.PromptTemplate) {
+	fmt.Print(color.YellowString("Configured (%s): \n", tmpl.Config.Selector()))
+	fmt.Println(strings.TrimSpace(tmpl.Source))
+}
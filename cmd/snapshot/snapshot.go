@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/snapshot"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	namespace string
+	filters   []string
+	output    string
+	tailLines int64
+)
+
+var SnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Captures the cluster state read by the analyzers into an archive",
+	Long: `The snapshot command captures every resource, event and container log the analyzers would read
+into a snapshot_*.tar.gz archive with a manifest. The archive can be analyzed later, without access
+to the cluster, with "k8sgpt analyze --from-snapshot".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, analyzerMap := analyzer.GetAnalyzerMap()
+
+		analyzers := filters
+		if len(analyzers) == 0 {
+			for name := range analyzerMap {
+				analyzers = append(analyzers, name)
+			}
+		} else {
+			for _, filter := range analyzers {
+				if _, ok := analyzerMap[filter]; !ok {
+					color.Red("Error: \"%s\" filter does not exist. Please run k8sgpt filters list.", filter)
+					os.Exit(1)
+				}
+			}
+		}
+		sort.Strings(analyzers)
+
+		kubecontext := viper.GetString("kubecontext")
+		kubeconfig := viper.GetString("kubeconfig")
+		client, err := kubernetes.NewClient(kubecontext, kubeconfig)
+		if err != nil {
+			color.Red("Error initialising kubernetes client: %v", err)
+			os.Exit(1)
+		}
+
+		if output == "" {
+			output = fmt.Sprintf("snapshot_%s.tar.gz", time.Now().Format("20060102150405"))
+		}
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		manifest, err := snapshot.Capture(context.Background(), client, snapshot.Options{
+			Namespace:     namespace,
+			Analyzers:     analyzers,
+			TailLines:     tailLines,
+			K8sGPTVersion: viper.GetString("Version"),
+		}, f)
+		if err != nil {
+			color.Red("Error: %v", err)
+			os.Exit(1)
+		}
+
+		for _, warning := range manifest.Warnings {
+			color.Yellow("Warning: %s", warning)
+		}
+		color.Green("Snapshot created successfully: %s (%d resource types, %d container logs)", output, len(manifest.Resources), len(manifest.Logs))
+	},
+}
+
+func init() {
+	// namespace flag
+	SnapshotCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace to capture")
+	// array of strings flag
+	SnapshotCmd.Flags().StringSliceVarP(&filters, "filter", "f", []string{}, "Capture only the resources read by these analyzers (e.g. Pod, PersistentVolumeClaim, Service, ReplicaSet)")
+	// output file flag
+	SnapshotCmd.Flags().StringVarP(&output, "output", "o", "", "Path of the snapshot archive (default snapshot_<timestamp>.tar.gz)")
+	// log tail lines flag
+	SnapshotCmd.Flags().Int64Var(&tailLines, "tail-lines", analyzer.LogTailLines(), "Number of log lines to capture per container")
+}
@@ -5,10 +5,12 @@ import (
 	"errors"
 	"github.com/aws/aws-sdk-go/service/bedrockruntime/bedrockruntimeiface"
 	"os"
+	"strconv"
 
 	"github.com/k8sgpt-ai/k8sgpt/pkg/ai/bedrock_support"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/bedrockruntime"
 )
@@ -301,12 +303,20 @@ func (a *AmazonBedRockClient) GetCompletion(ctx context.Context, prompt string)
 		ContentType: aws.String("application/json"),
 		Accept:      aws.String("application/json"),
 	}
-	// Invoke the model
-	resp, err := a.client.InvokeModelWithContext(ctx, params)
+	// Invoke the model, Bedrock reports the usage in the response headers.
+	var inputTokens, outputTokens string
+	resp, err := a.client.InvokeModelWithContext(ctx, params,
+		request.WithGetResponseHeader("X-Amzn-Bedrock-Input-Token-Count", &inputTokens),
+		request.WithGetResponseHeader("X-Amzn-Bedrock-Output-Token-Count", &outputTokens))
 
 	if err != nil {
 		return "", err
 	}
+	promptTokens, inputErr := strconv.Atoi(inputTokens)
+	completionTokens, outputErr := strconv.Atoi(outputTokens)
+	if inputErr == nil && outputErr == nil {
+		reportUsage(ctx, Usage{PromptTokens: promptTokens, CompletionTokens: completionTokens})
+	}
 
 	// Parse the response
 	return a.model.Response.ParseResponse(resp.Body)
@@ -3,8 +3,8 @@ package ai
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
-	"net/url"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -37,18 +37,12 @@ func (c *AzureAIClient) Configure(config IAIConfig) error {
 
 	}
 
-	if proxyEndpoint != "" {
-		proxyUrl, err := url.Parse(proxyEndpoint)
-		if err != nil {
-			return err
-		}
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		}
-
-		defaultConfig.HTTPClient = &http.Client{
-			Transport: transport,
-		}
+	transport, err := proxyTransport(proxyEndpoint)
+	if err != nil {
+		return err
+	}
+	defaultConfig.HTTPClient = &http.Client{
+		Transport: NewRetryTransport(transport),
 	}
 	if orgId != "" {
 		defaultConfig.OrgID = orgId
@@ -64,9 +58,8 @@ func (c *AzureAIClient) Configure(config IAIConfig) error {
 	return nil
 }
 
-func (c *AzureAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
-	// Create a completion request
-	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+func (c *AzureAIClient) completionRequest(prompt string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
 		Model: c.model,
 		Messages: []openai.ChatCompletionMessage{
 			{
@@ -75,13 +68,30 @@ func (c *AzureAIClient) GetCompletion(ctx context.Context, prompt string) (strin
 			},
 		},
 		Temperature: c.temperature,
-	})
+	}
+}
+
+func (c *AzureAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	// Create a completion request
+	resp, err := c.client.CreateChatCompletion(ctx, c.completionRequest(prompt))
 	if err != nil {
 		return "", err
 	}
+	reportOpenAIUsage(ctx, &resp.Usage)
 	return resp.Choices[0].Message.Content, nil
 }
 
+func (c *AzureAIClient) StreamCompletion(ctx context.Context, prompt string, w io.Writer) (string, error) {
+	req := c.completionRequest(prompt)
+	req.Stream = true
+	stream, err := c.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+	return readChatCompletionStream(ctx, stream, w)
+}
+
 func (c *AzureAIClient) GetName() string {
 	return azureAIClientName
 }
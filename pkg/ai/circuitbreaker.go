@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// circuitBreakerThreshold is the number of consecutive failures opening
+	// the circuit breaker of a provider.
+	circuitBreakerThreshold = 3
+	// circuitBreakerCooldown is how long a provider is skipped once its
+	// circuit breaker opened, before a single request is let through.
+	circuitBreakerCooldown = 30 * time.Second
+)
+
+var (
+	circuitBreakersMutex sync.Mutex
+	circuitBreakers      = map[string]*circuitBreaker{}
+)
+
+type circuitBreaker struct {
+	mutex    sync.Mutex
+	now      func() time.Time
+	failures int
+	// openUntil is when the next request may be tried, while open.
+	openUntil time.Time
+	// probing is set while the request trying a half open provider runs.
+	probing bool
+}
+
+// circuitBreakerFor returns the circuit breaker shared by all the requests to
+// the provider.
+func circuitBreakerFor(provider string) *circuitBreaker {
+	circuitBreakersMutex.Lock()
+	defer circuitBreakersMutex.Unlock()
+	breaker, ok := circuitBreakers[provider]
+	if !ok {
+		breaker = &circuitBreaker{now: time.Now}
+		circuitBreakers[provider] = breaker
+	}
+	return breaker
+}
+
+// allow reports whether a request may be sent to the provider: always when
+// the breaker is closed, never while open, and a single one once the
+// cooldown is over.
+func (b *circuitBreaker) allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.failures < circuitBreakerThreshold {
+		return true
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *circuitBreaker) success() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *circuitBreaker) failure() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.failures++
+	b.probing = false
+	if b.failures >= circuitBreakerThreshold {
+		b.openUntil = b.now().Add(circuitBreakerCooldown)
+	}
+}
+
+// release ends a request without counting it as a success or a failure, e.g.
+// when it was canceled, so that another request may probe the provider.
+func (b *circuitBreaker) release() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probing = false
+}
@@ -72,6 +72,13 @@ func (c *CohereClient) GetCompletion(ctx context.Context, prompt string) (string
 	if err != nil {
 		return "", err
 	}
+	if response.Meta != nil && response.Meta.Tokens != nil &&
+		response.Meta.Tokens.InputTokens != nil && response.Meta.Tokens.OutputTokens != nil {
+		reportUsage(ctx, Usage{
+			PromptTokens:     int(*response.Meta.Tokens.InputTokens),
+			CompletionTokens: int(*response.Meta.Tokens.OutputTokens),
+		})
+	}
 	return response.Text, nil
 }
 
@@ -142,6 +142,21 @@ func (c *CustomRestClient) GetCompletion(ctx context.Context, prompt string) (st
 	return result.Response, nil
 }
 
+type languageKey struct{}
+
+// WithLanguage attaches the language of the explanations to ctx, sent to the
+// customrest backend along with the prompt.
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// rawPrompt wraps a prompt in the JSON expected by the customrest backend,
+// with the language and failures attached to ctx.
+func rawPrompt(ctx context.Context, prompt string) string {
+	language, _ := ctx.Value(languageKey{}).(string)
+	return fmt.Sprintf(PromptMap["raw"], language, failures(ctx, prompt), prompt)
+}
+
 func (c *CustomRestClient) GetName() string {
 	return CustomRestClientName
 }
@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+// FallbackProvider is a configured client in a fallback chain.
+type FallbackProvider struct {
+	Client IAI
+	// Limiter limits the requests to the provider, if not nil.
+	Limiter *rate.Limiter
+}
+
+// FallbackClient asks the first provider of the chain whose circuit breaker
+// is closed, and falls back to the next providers when it fails.
+type FallbackClient struct {
+	providers []FallbackProvider
+	breakers  []*circuitBreaker
+}
+
+// NewFallbackClient returns a client trying the providers in order.
+func NewFallbackClient(providers []FallbackProvider) *FallbackClient {
+	c := &FallbackClient{providers: providers}
+	for _, provider := range providers {
+		c.breakers = append(c.breakers, circuitBreakerFor(provider.Client.GetName()))
+	}
+	return c
+}
+
+// Configure is a no-op, the providers of the chain are already configured.
+func (c *FallbackClient) Configure(_ IAIConfig) error {
+	return nil
+}
+
+func (c *FallbackClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	completion, err := c.complete(ctx, prompt, nil)
+	return completion.Text, err
+}
+
+func (c *FallbackClient) StreamCompletion(ctx context.Context, prompt string, w io.Writer) (string, error) {
+	completion, err := c.complete(ctx, prompt, w)
+	return completion.Text, err
+}
+
+// GetName returns the name of the primary provider.
+func (c *FallbackClient) GetName() string {
+	return c.providers[0].Client.GetName()
+}
+
+// Providers returns the names of the providers in the order they are tried.
+func (c *FallbackClient) Providers() []string {
+	names := make([]string, 0, len(c.providers))
+	for _, provider := range c.providers {
+		names = append(names, provider.Client.GetName())
+	}
+	return names
+}
+
+func (c *FallbackClient) Close() {
+	for _, provider := range c.providers {
+		provider.Client.Close()
+	}
+}
+
+func (c *FallbackClient) complete(ctx context.Context, prompt string, w io.Writer) (CompletionResult, error) {
+	var errs []string
+	// unknown is set while every provider asked only missed in its
+	// knowledge base, see ErrNoKBEntry.
+	unknown := true
+	for i, provider := range c.providers {
+		name := provider.Client.GetName()
+		if !c.breakers[i].allow() {
+			errs = append(errs, fmt.Sprintf("%s: circuit breaker open", name))
+			unknown = false
+			continue
+		}
+		if provider.Limiter != nil {
+			if err := provider.Limiter.Wait(ctx); err != nil {
+				c.breakers[i].release()
+				return CompletionResult{}, err
+			}
+		}
+
+		var out io.Writer
+		written := &countingWriter{w: w}
+		if w != nil {
+			out = written
+		}
+		completion, err := complete(ctx, provider.Client, prompt, out)
+		if err == nil {
+			c.breakers[i].success()
+			return completion, nil
+		}
+		if ctx.Err() != nil {
+			// The run was canceled, the provider did not fail.
+			c.breakers[i].release()
+			return CompletionResult{}, err
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+		if errors.Is(err, ErrNoKBEntry) {
+			// The provider works, it doesn't know these failures.
+			c.breakers[i].release()
+			continue
+		}
+		c.breakers[i].failure()
+		unknown = false
+		if written.n > 0 && i < len(c.providers)-1 {
+			// The partial answer can't be taken back, separate it from the
+			// answer of the next provider.
+			fmt.Fprintf(w, "\n[%s failed: %v, asking the next provider]\n", name, err)
+		}
+	}
+	if unknown {
+		return CompletionResult{}, ErrNoKBEntry
+	}
+	return CompletionResult{}, fmt.Errorf("all AI providers failed: %s", strings.Join(errs, "; "))
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
+
+// CompletionResult is a completion and how it was generated.
+type CompletionResult struct {
+	Text string
+	// Provider is the name of the provider that generated the completion.
+	Provider string
+	Usage    Usage
+}
+
+// Completion asks the client to complete the prompt, streaming it to w if not
+// nil.
+func Completion(ctx context.Context, client IAI, prompt string, w io.Writer) (CompletionResult, error) {
+	if recorder, ok := client.(*Recorder); ok {
+		completion, err := Completion(ctx, recorder.client, prompt, w)
+		if err != nil {
+			return CompletionResult{}, err
+		}
+		recorder.record(completion.Provider, prompt, completion.Text, completion.Usage)
+		return completion, nil
+	}
+	if fallback, ok := client.(*FallbackClient); ok {
+		return fallback.complete(ctx, prompt, w)
+	}
+	return complete(ctx, client, prompt, w)
+}
+
+func complete(ctx context.Context, client IAI, prompt string, w io.Writer) (CompletionResult, error) {
+	if client.GetName() == CustomRestClientName {
+		prompt = rawPrompt(ctx, prompt)
+	}
+	var usage Usage
+	ctx = context.WithValue(ctx, usageKey{}, &usage)
+
+	var completion string
+	var err error
+	if w != nil {
+		completion, err = StreamCompletion(ctx, client, prompt, w)
+	} else {
+		completion, err = client.GetCompletion(ctx, prompt)
+	}
+	if err != nil {
+		return CompletionResult{}, err
+	}
+	if usage == (Usage{}) {
+		usage = Usage{
+			PromptTokens:     EstimateTokens(prompt),
+			CompletionTokens: EstimateTokens(completion),
+			Estimated:        true,
+		}
+	}
+	return CompletionResult{Text: completion, Provider: client.GetName(), Usage: usage}, nil
+}
+
+// ProviderNames returns the names of the providers the client may use, in
+// the order they are tried.
+func ProviderNames(client IAI) []string {
+	if recorder, ok := client.(*Recorder); ok {
+		client = recorder.client
+	}
+	if fallback, ok := client.(*FallbackClient); ok {
+		return fallback.Providers()
+	}
+	return []string{client.GetName()}
+}
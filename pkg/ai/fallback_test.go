@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClient struct {
+	NoOpAIClient
+	name  string
+	err   error
+	calls int
+}
+
+func (c *fakeClient) GetCompletion(_ context.Context, prompt string) (string, error) {
+	c.calls++
+	if c.err != nil {
+		return "", c.err
+	}
+	return c.name + ": " + prompt, nil
+}
+
+func (c *fakeClient) GetName() string {
+	return c.name
+}
+
+func TestFallbackClient(t *testing.T) {
+	primary := &fakeClient{name: t.Name() + "-primary", err: errors.New("service unavailable")}
+	secondary := &fakeClient{name: t.Name() + "-secondary"}
+	client := NewFallbackClient([]FallbackProvider{{Client: primary}, {Client: secondary}})
+	require.Equal(t, primary.name, client.GetName())
+	require.Equal(t, []string{primary.name, secondary.name}, ProviderNames(client))
+
+	now := time.Now()
+	client.breakers[0].now = func() time.Time { return now }
+
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		completion, err := Completion(context.Background(), client, "prompt", nil)
+		require.NoError(t, err)
+		require.Equal(t, secondary.name+": prompt", completion.Text)
+		require.Equal(t, secondary.name, completion.Provider)
+	}
+	require.Equal(t, circuitBreakerThreshold, primary.calls)
+
+	// The circuit breaker of the primary provider is open.
+	_, err := Completion(context.Background(), client, "prompt", nil)
+	require.NoError(t, err)
+	require.Equal(t, circuitBreakerThreshold, primary.calls)
+
+	// Once the cooldown is over, a request is tried again.
+	now = now.Add(circuitBreakerCooldown)
+	primary.err = nil
+	var output strings.Builder
+	completion, err := Completion(context.Background(), client, "prompt", &output)
+	require.NoError(t, err)
+	require.Equal(t, primary.name, completion.Provider)
+	require.Equal(t, completion.Text, output.String())
+	require.Equal(t, circuitBreakerThreshold+1, primary.calls)
+	require.True(t, client.breakers[0].allow())
+}
+
+func TestFallbackClient_AllFail(t *testing.T) {
+	primary := &fakeClient{name: t.Name() + "-primary", err: errors.New("service unavailable")}
+	secondary := &fakeClient{name: t.Name() + "-secondary", err: errors.New("invalid api key")}
+	client := NewFallbackClient([]FallbackProvider{{Client: primary}, {Client: secondary}})
+
+	_, err := client.GetCompletion(context.Background(), "prompt")
+	require.EqualError(t, err, "all AI providers failed: "+primary.name+": service unavailable; "+secondary.name+": invalid api key")
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	now := time.Now()
+	breaker := &circuitBreaker{now: func() time.Time { return now }}
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		require.True(t, breaker.allow())
+		breaker.failure()
+	}
+	require.False(t, breaker.allow())
+
+	now = now.Add(circuitBreakerCooldown)
+	require.True(t, breaker.allow())
+	// A single request is let through while half open.
+	require.False(t, breaker.allow())
+	breaker.failure()
+	require.False(t, breaker.allow())
+
+	now = now.Add(circuitBreakerCooldown)
+	require.True(t, breaker.allow())
+	breaker.success()
+	require.True(t, breaker.allow())
+	require.True(t, breaker.allow())
+}
+
+// partialStreamClient streams part of an answer and fails.
+type partialStreamClient struct {
+	fakeClient
+}
+
+func (c *partialStreamClient) StreamCompletion(_ context.Context, _ string, w io.Writer) (string, error) {
+	_, _ = io.WriteString(w, "Error: the ima")
+	return "", errors.New("connection reset")
+}
+
+func TestFallbackClient_PartialStream(t *testing.T) {
+	primary := &partialStreamClient{fakeClient{name: t.Name() + "-primary"}}
+	secondary := &fakeClient{name: t.Name() + "-secondary"}
+	client := NewFallbackClient([]FallbackProvider{{Client: primary}, {Client: secondary}})
+
+	var output strings.Builder
+	completion, err := Completion(context.Background(), client, "prompt", &output)
+	require.NoError(t, err)
+	require.Equal(t, secondary.name+": prompt", completion.Text)
+	require.Equal(t, "Error: the ima\n["+primary.name+" failed: connection reset, asking the next provider]\n"+completion.Text, output.String())
+}
+
+func TestFallbackClient_CanceledProbe(t *testing.T) {
+	primary := &fakeClient{name: t.Name() + "-primary", err: errors.New("service unavailable")}
+	secondary := &fakeClient{name: t.Name() + "-secondary"}
+	client := NewFallbackClient([]FallbackProvider{{Client: primary}, {Client: secondary}})
+	now := time.Now()
+	client.breakers[0].now = func() time.Time { return now }
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		_, err := Completion(context.Background(), client, "prompt", nil)
+		require.NoError(t, err)
+	}
+
+	// The probe is canceled, the provider may still be probed by the next
+	// request.
+	now = now.Add(circuitBreakerCooldown)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Completion(ctx, client, "prompt", nil)
+	require.EqualError(t, err, "service unavailable")
+	require.True(t, client.breakers[0].allow())
+}
+
+func TestFallbackClient_UnknownToKB(t *testing.T) {
+	kb := &KBClient{}
+	require.NoError(t, kb.Configure(&AIProvider{Name: "kb"}))
+	client := NewFallbackClient([]FallbackProvider{{Client: kb}})
+	for i := 0; i < circuitBreakerThreshold; i++ {
+		_, err := Completion(context.Background(), client, "--- Deployment has 1 replicas but 2 are available ---", nil)
+		require.ErrorIs(t, err, ErrNoKBEntry)
+	}
+
+	// Unknown failures don't open the circuit breaker of the kb backend.
+	completion, err := Completion(context.Background(), client, `--- Back-off pulling image "nginx:1.99" ---`, nil)
+	require.NoError(t, err)
+	require.Contains(t, completion.Text, "nginx:1.99")
+}
+
+func TestFallbackClient_CustomRest(t *testing.T) {
+	var requests []CustomRestRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request CustomRestRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+		_, _ = w.Write([]byte(`{"response": "customrest: ` + request.Prompt + `"}`))
+	}))
+	defer server.Close()
+	customRest := &CustomRestClient{}
+	require.NoError(t, customRest.Configure(&AIProvider{BaseURL: server.URL}))
+	failing := &fakeClient{name: t.Name() + "-failing", err: errors.New("service unavailable")}
+	other := &fakeClient{name: t.Name() + "-other"}
+	ctx := WithLanguage(WithFailures(context.Background(), "the image does not exist"), "english")
+
+	// The customrest fallback gets the prompt wrapped with its failures and
+	// language.
+	client := NewFallbackClient([]FallbackProvider{{Client: failing}, {Client: customRest}})
+	completion, err := Completion(ctx, client, "explain", nil)
+	require.NoError(t, err)
+	require.Equal(t, "customrest: explain", completion.Text)
+	require.Len(t, requests, 1)
+	require.Equal(t, "the image does not exist", requests[0].Options["message"])
+	require.Equal(t, "english", requests[0].Options["language"])
+
+	// The other providers get the prompt as is.
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client = NewFallbackClient([]FallbackProvider{{Client: customRest}, {Client: other}})
+	completion, err = Completion(ctx, client, "explain", nil)
+	require.NoError(t, err)
+	require.Equal(t, other.name+": explain", completion.Text)
+}
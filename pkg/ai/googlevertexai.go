@@ -132,6 +132,12 @@ func (g *GoogleVertexAIClient) GetCompletion(ctx context.Context, prompt string)
 	if err != nil {
 		return "", err
 	}
+	if resp.UsageMetadata != nil {
+		reportUsage(ctx, Usage{
+			PromptTokens:     int(resp.UsageMetadata.PromptTokenCount),
+			CompletionTokens: int(resp.UsageMetadata.CandidatesTokenCount),
+		})
+	}
 
 	if len(resp.Candidates) == 0 {
 		if resp.PromptFeedback.BlockReason > 0 {
@@ -0,0 +1,355 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const httpClientName = "http"
+
+// The streaming formats of the http backend.
+const (
+	// StreamSSE is a stream of server-sent events "data: {...}", ending with
+	// "data: [DONE]".
+	StreamSSE = "sse"
+	// StreamNDJSON is a stream of JSON objects, one per line.
+	StreamNDJSON = "ndjson"
+)
+
+// HTTPPresets are the built-in configurations of the http backend.
+var HTTPPresets = map[string]HTTPBackendConfig{
+	// openai-chat is the chat completion API of OpenAI, also served by vLLM,
+	// LiteLLM and most gateways.
+	"openai-chat": {
+		Path: "/chat/completions",
+		Body: `{"model": {{json .model}}, "messages": [{"role": "user", "content": {{json .prompt}}}], ` +
+			`"temperature": {{.temperature}}, "top_p": {{.topP}}, "max_tokens": {{.maxTokens}}, "stream": {{.stream}}}`,
+		AuthHeader:   "Authorization: Bearer {{.password}}",
+		ResponsePath: "choices.0.message.content",
+		Stream:       StreamSSE,
+		StreamPath:   "choices.0.delta.content",
+	},
+}
+
+// HTTPBackendConfig declares how the http backend talks to an API, in the
+// `http` section of the provider. Its unset fields are taken from Preset,
+// openai-chat by default.
+//
+// Body and AuthHeader are text/templates executed with the variables model,
+// prompt, temperature, topP, topK, maxTokens, stream and password; the json
+// function encodes a value, e.g. `{"input": {{json .prompt}}}`.
+type HTTPBackendConfig struct {
+	Preset string `mapstructure:"preset" yaml:"preset,omitempty"`
+	// Path is appended to the base URL of the provider.
+	Path string `mapstructure:"path" yaml:"path,omitempty"`
+	// Body is the JSON body of the requests.
+	Body string `mapstructure:"body" yaml:"body,omitempty"`
+	// AuthHeader is the header carrying the password, e.g.
+	// "api-key: {{.password}}". It is not sent without password.
+	AuthHeader string `mapstructure:"authheader" yaml:"authheader,omitempty"`
+	// ResponsePath is the path of the text in the JSON response, with the
+	// keys and indexes separated by dots, e.g. "choices.0.message.content".
+	ResponsePath string `mapstructure:"responsepath" yaml:"responsepath,omitempty"`
+	// Stream is the streaming format, sse or ndjson, or "none" to not stream.
+	// It defaults to none with a custom Body.
+	Stream string `mapstructure:"stream" yaml:"stream,omitempty"`
+	// StreamPath is the path of the text in each streamed object.
+	StreamPath string `mapstructure:"streampath" yaml:"streampath,omitempty"`
+}
+
+// withPreset fills the unset fields of the configuration from its preset.
+func (c HTTPBackendConfig) withPreset() (HTTPBackendConfig, error) {
+	name := c.Preset
+	if name == "" {
+		name = "openai-chat"
+	}
+	preset, ok := HTTPPresets[name]
+	if !ok {
+		return c, fmt.Errorf("unknown http preset %q", name)
+	}
+	if c.Body != "" && c.Stream == "" {
+		// A custom body is unlikely to stream in the format of the preset.
+		c.Stream = "none"
+	}
+	for _, field := range []struct{ value, preset *string }{
+		{&c.Path, &preset.Path},
+		{&c.Body, &preset.Body},
+		{&c.AuthHeader, &preset.AuthHeader},
+		{&c.ResponsePath, &preset.ResponsePath},
+		{&c.Stream, &preset.Stream},
+		{&c.StreamPath, &preset.StreamPath},
+	} {
+		if *field.value == "" {
+			*field.value = *field.preset
+		}
+	}
+	if c.Stream == "none" {
+		c.Stream, c.StreamPath = "", ""
+	}
+	return c, nil
+}
+
+// httpBackendConfigurer is implemented by the configurations of the http
+// backend.
+type httpBackendConfigurer interface {
+	GetHTTPBackend() *HTTPBackendConfig
+}
+
+// HTTPClient is a backend whose requests and responses are declared in the
+// configuration, see HTTPBackendConfig.
+type HTTPClient struct {
+	nopCloser
+	client     *http.Client
+	url        string
+	config     HTTPBackendConfig
+	body       *template.Template
+	authHeader *template.Template
+	vars       map[string]interface{}
+}
+
+func (c *HTTPClient) Configure(config IAIConfig) error {
+	backend := HTTPBackendConfig{}
+	if configurer, ok := config.(httpBackendConfigurer); ok && configurer.GetHTTPBackend() != nil {
+		backend = *configurer.GetHTTPBackend()
+	}
+	backend, err := backend.withPreset()
+	if err != nil {
+		return err
+	}
+	if backend.Stream != "" && backend.Stream != StreamSSE && backend.Stream != StreamNDJSON {
+		return fmt.Errorf("unknown http stream format %q, expected %s, %s or none", backend.Stream, StreamSSE, StreamNDJSON)
+	}
+	c.config = backend
+
+	funcs := template.FuncMap{"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	}}
+	if c.body, err = template.New("body").Funcs(funcs).Option("missingkey=error").Parse(backend.Body); err != nil {
+		return fmt.Errorf("invalid http body: %w", err)
+	}
+	if c.authHeader, err = template.New("authheader").Funcs(funcs).Option("missingkey=error").Parse(backend.AuthHeader); err != nil {
+		return fmt.Errorf("invalid http authheader: %w", err)
+	}
+
+	if config.GetBaseURL() == "" {
+		return errors.New("the http backend requires a base URL")
+	}
+	c.url = strings.TrimSuffix(config.GetBaseURL(), "/") + backend.Path
+	if _, err := url.Parse(c.url); err != nil {
+		return err
+	}
+	transport, err := proxyTransport(config.GetProxyEndpoint())
+	if err != nil {
+		return err
+	}
+	c.client = &http.Client{
+		Transport: &OpenAIHeaderTransport{
+			Origin:  NewRetryTransport(transport),
+			Headers: config.GetCustomHeaders(),
+		},
+	}
+
+	c.vars = map[string]interface{}{
+		"model":       config.GetModel(),
+		"temperature": config.GetTemperature(),
+		"topP":        config.GetTopP(),
+		"topK":        config.GetTopK(),
+		"maxTokens":   config.GetMaxTokens(),
+		"password":    config.GetPassword(),
+	}
+	return nil
+}
+
+// request builds the request of a prompt.
+func (c *HTTPClient) request(ctx context.Context, prompt string, stream bool) (*http.Request, error) {
+	vars := map[string]interface{}{"prompt": prompt, "stream": stream}
+	for name, value := range c.vars {
+		vars[name] = value
+	}
+	var body bytes.Buffer
+	if err := c.body.Execute(&body, vars); err != nil {
+		return nil, fmt.Errorf("invalid http body: %w", err)
+	}
+	if !json.Valid(body.Bytes()) {
+		return nil, errors.New("invalid http body, not JSON")
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, &body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if c.vars["password"] != "" && c.config.AuthHeader != "" {
+		var header strings.Builder
+		if err := c.authHeader.Execute(&header, vars); err != nil {
+			return nil, fmt.Errorf("invalid http authheader: %w", err)
+		}
+		name, value, found := strings.Cut(header.String(), ":")
+		if !found {
+			return nil, errors.New(`invalid http authheader, expected "name: value"`)
+		}
+		request.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return request, nil
+}
+
+// do sends a request and returns its response, or an error if it failed.
+func (c *HTTPClient) do(request *http.Request) (*http.Response, error) {
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= http.StatusBadRequest {
+		defer response.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		// The status code is reported as the other SDKs do, see IsRateLimited.
+		return nil, fmt.Errorf("request failed with status code: %d: %s", response.StatusCode, bytes.TrimSpace(body))
+	}
+	return response, nil
+}
+
+func (c *HTTPClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	request, err := c.request(ctx, prompt, false)
+	if err != nil {
+		return "", err
+	}
+	response, err := c.do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var body interface{}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid response: %w", err)
+	}
+	text, err := jsonPath(body, c.config.ResponsePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid response: %w", err)
+	}
+	return text, nil
+}
+
+func (c *HTTPClient) StreamCompletion(ctx context.Context, prompt string, w io.Writer) (string, error) {
+	if c.config.Stream == "" {
+		completion, err := c.GetCompletion(ctx, prompt)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.WriteString(w, completion)
+		return completion, err
+	}
+	request, err := c.request(ctx, prompt, true)
+	if err != nil {
+		return "", err
+	}
+	response, err := c.do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	var completion strings.Builder
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if c.config.Stream == StreamSSE {
+			data, found := strings.CutPrefix(line, "data:")
+			if !found {
+				// Comments, event names and the blank lines between events.
+				continue
+			}
+			line = strings.TrimSpace(data)
+			if line == "[DONE]" {
+				break
+			}
+		}
+		if line == "" {
+			continue
+		}
+		var chunk interface{}
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return completion.String(), fmt.Errorf("invalid streamed response: %w", err)
+		}
+		if object, ok := chunk.(map[string]interface{}); ok && object["error"] != nil {
+			message, _ := json.Marshal(object["error"])
+			return completion.String(), fmt.Errorf("streamed error: %s", message)
+		}
+		// Some chunks carry no text, e.g. the role of the answer.
+		text, err := jsonPath(chunk, c.config.StreamPath)
+		if err != nil {
+			continue
+		}
+		completion.WriteString(text)
+		if _, err := io.WriteString(w, text); err != nil {
+			return completion.String(), err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return completion.String(), err
+	}
+	if completion.Len() == 0 {
+		return "", fmt.Errorf("the stream ended without text at %s", c.config.StreamPath)
+	}
+	return completion.String(), nil
+}
+
+func (c *HTTPClient) GetName() string {
+	return httpClientName
+}
+
+// jsonPath returns the string at path in a decoded JSON value, the keys and
+// indexes of the path being separated by dots.
+func jsonPath(value interface{}, path string) (string, error) {
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				var ok bool
+				if value, ok = v[key]; !ok {
+					return "", fmt.Errorf("no %s in %s", key, path)
+				}
+			case []interface{}:
+				index, err := strconv.Atoi(key)
+				if err != nil || index < 0 || index >= len(v) {
+					return "", fmt.Errorf("no %s in %s", key, path)
+				}
+				value = v[index]
+			default:
+				return "", fmt.Errorf("no %s in %s", key, path)
+			}
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", fmt.Errorf("%s is null", path)
+	default:
+		return "", fmt.Errorf("%s is not a string", path)
+	}
+}
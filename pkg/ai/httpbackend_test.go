@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPClient_OpenAIChatPreset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chat/completions", r.URL.Path)
+		require.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		var body struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+			MaxTokens int  `json:"max_tokens"`
+			Stream    bool `json:"stream"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "qwen2.5", body.Model)
+		require.Equal(t, 1024, body.MaxTokens)
+		require.Equal(t, "why does \"web\" fail?\n", body.Messages[0].Content)
+
+		if !body.Stream {
+			fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "Error: the image does not exist."}}]}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"choices": [{"delta": {"role": "assistant"}}]}`+"\n\n")
+		for _, token := range []string{"Error: ", "the image ", "does not exist."} {
+			fmt.Fprintf(w, `data: {"choices": [{"delta": {"content": %q}}]}`+"\n\n", token)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := &HTTPClient{}
+	require.NoError(t, client.Configure(&AIProvider{
+		Name:      "http",
+		Model:     "qwen2.5",
+		Password:  "sk-test",
+		BaseURL:   server.URL + "/v1/",
+		MaxTokens: 1024,
+	}))
+
+	completion, err := client.GetCompletion(context.Background(), "why does \"web\" fail?\n")
+	require.NoError(t, err)
+	require.Equal(t, "Error: the image does not exist.", completion)
+
+	require.True(t, CanStream(client))
+	var output strings.Builder
+	completion, err = client.StreamCompletion(context.Background(), "why does \"web\" fail?\n", &output)
+	require.NoError(t, err)
+	require.Equal(t, "Error: the image does not exist.", completion)
+	require.Equal(t, completion, output.String())
+}
+
+func TestHTTPClient_Declared(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/generate", r.URL.Path)
+		require.Equal(t, "sk-test", r.Header.Get("X-Api-Key"))
+		require.Empty(t, r.Header.Get("Authorization"))
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, map[string]interface{}{"input": "foo prompt", "stream": body["stream"]}, body)
+
+		if body["stream"] == false {
+			fmt.Fprint(w, `{"output": {"text": "Error: the image does not exist."}}`)
+			return
+		}
+		for _, token := range []string{"Error: ", "the image ", "does not exist."} {
+			fmt.Fprintf(w, `{"token": %q}`+"\n", token)
+		}
+		fmt.Fprint(w, `{"done": true}`+"\n")
+	}))
+	defer server.Close()
+
+	client := &HTTPClient{}
+	require.NoError(t, client.Configure(&AIProvider{
+		Name:     "http",
+		Password: "sk-test",
+		BaseURL:  server.URL,
+		HTTP: &HTTPBackendConfig{
+			Path:         "/generate",
+			Body:         `{"input": {{json .prompt}}, "stream": {{.stream}}}`,
+			AuthHeader:   "X-Api-Key: {{.password}}",
+			ResponsePath: "output.text",
+			Stream:       StreamNDJSON,
+			StreamPath:   "token",
+		},
+	}))
+
+	completion, err := client.GetCompletion(context.Background(), "foo prompt")
+	require.NoError(t, err)
+	require.Equal(t, "Error: the image does not exist.", completion)
+
+	var output strings.Builder
+	completion, err = client.StreamCompletion(context.Background(), "foo prompt", &output)
+	require.NoError(t, err)
+	require.Equal(t, "Error: the image does not exist.", completion)
+	require.Equal(t, completion, output.String())
+}
+
+func TestHTTPClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bad":
+			http.Error(w, `{"error": "unknown model"}`, http.StatusBadRequest)
+			return
+		case "/stream-error":
+			fmt.Fprint(w, `data: {"error": {"message": "quota exceeded"}}`+"\n\n")
+			return
+		case "/stream-empty":
+			fmt.Fprint(w, `data: {"choices": [{"delta": {"role": "assistant"}}]}`+"\n\ndata: [DONE]\n\n")
+			return
+		}
+		fmt.Fprint(w, `{"choices": []}`)
+	}))
+	defer server.Close()
+
+	client := &HTTPClient{}
+	require.NoError(t, client.Configure(&AIProvider{BaseURL: server.URL, HTTP: &HTTPBackendConfig{Stream: "none"}}))
+	require.Empty(t, client.config.Stream)
+	_, err := client.GetCompletion(context.Background(), "foo prompt")
+	require.EqualError(t, err, "invalid response: no 0 in choices.0.message.content")
+
+	require.NoError(t, client.Configure(&AIProvider{BaseURL: server.URL, HTTP: &HTTPBackendConfig{Path: "/bad"}}))
+	_, err = client.GetCompletion(context.Background(), "foo prompt")
+	require.EqualError(t, err, `request failed with status code: 400: {"error": "unknown model"}`)
+
+	require.NoError(t, client.Configure(&AIProvider{BaseURL: server.URL, HTTP: &HTTPBackendConfig{Body: `{"input": {{.prompt}}}`}}))
+	_, err = client.GetCompletion(context.Background(), "foo prompt")
+	require.EqualError(t, err, "invalid http body, not JSON")
+
+	require.NoError(t, client.Configure(&AIProvider{BaseURL: server.URL, HTTP: &HTTPBackendConfig{Path: "/stream-error"}}))
+	_, err = client.StreamCompletion(context.Background(), "foo prompt", io.Discard)
+	require.EqualError(t, err, `streamed error: {"message":"quota exceeded"}`)
+	require.NoError(t, client.Configure(&AIProvider{BaseURL: server.URL, HTTP: &HTTPBackendConfig{Path: "/stream-empty"}}))
+	_, err = client.StreamCompletion(context.Background(), "foo prompt", io.Discard)
+	require.EqualError(t, err, "the stream ended without text at choices.0.delta.content")
+
+	require.EqualError(t, client.Configure(&AIProvider{BaseURL: server.URL, HTTP: &HTTPBackendConfig{Preset: "anthropic"}}), `unknown http preset "anthropic"`)
+	require.EqualError(t, client.Configure(&AIProvider{BaseURL: server.URL, HTTP: &HTTPBackendConfig{Stream: "websocket"}}),
+		`unknown http stream format "websocket", expected sse, ndjson or none`)
+	require.EqualError(t, client.Configure(&AIProvider{}), "the http backend requires a base URL")
+}
+
+func TestHTTPBackendConfig_CustomBody(t *testing.T) {
+	config, err := HTTPBackendConfig{Body: `{"input": {{json .prompt}}}`, ResponsePath: "output"}.withPreset()
+	require.NoError(t, err)
+	require.Equal(t, HTTPBackendConfig{
+		Path:         "/chat/completions",
+		Body:         `{"input": {{json .prompt}}}`,
+		AuthHeader:   "Authorization: Bearer {{.password}}",
+		ResponsePath: "output",
+	}, config)
+
+	config, err = HTTPBackendConfig{Body: `{"input": {{json .prompt}}}`, Stream: StreamSSE}.withPreset()
+	require.NoError(t, err)
+	require.Equal(t, StreamSSE, config.Stream)
+	require.Equal(t, "choices.0.delta.content", config.StreamPath)
+}
@@ -15,6 +15,7 @@ package ai
 
 import (
 	"context"
+	"io"
 	"net/http"
 )
 
@@ -34,6 +35,9 @@ var (
 		&OCIGenAIClient{},
 		&CustomRestClient{},
 		&IBMWatsonxAIClient{},
+		&ReplayClient{},
+		&HTTPClient{},
+		&KBClient{},
 	}
 	Backends = []string{
 		openAIClientName,
@@ -50,6 +54,9 @@ var (
 		ociClientName,
 		CustomRestClientName,
 		ibmWatsonxAIClientName,
+		replayClientName,
+		httpClientName,
+		kbClientName,
 	}
 )
 
@@ -67,6 +74,41 @@ type IAI interface {
 	Close()
 }
 
+// IStreamingAI is implemented by the clients able to stream completions.
+type IStreamingAI interface {
+	IAI
+	// StreamCompletion generates text based on prompt, writing it to w as it
+	// is generated, and returns the whole text.
+	StreamCompletion(ctx context.Context, prompt string, w io.Writer) (string, error)
+}
+
+// StreamCompletion streams the completion of prompt to w if the client
+// supports it, and otherwise writes the whole completion once it is done.
+func StreamCompletion(ctx context.Context, client IAI, prompt string, w io.Writer) (string, error) {
+	if streaming, ok := client.(IStreamingAI); ok {
+		return streaming.StreamCompletion(ctx, prompt, w)
+	}
+	completion, err := client.GetCompletion(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.WriteString(w, completion)
+	return completion, err
+}
+
+// CanStream reports whether the client streams completions. A fallback
+// chain streams if its primary provider does.
+func CanStream(client IAI) bool {
+	if recorder, ok := client.(*Recorder); ok {
+		client = recorder.client
+	}
+	if fallback, ok := client.(*FallbackClient); ok {
+		client = fallback.providers[0].Client
+	}
+	_, ok := client.(IStreamingAI)
+	return ok
+}
+
 type nopCloser struct{}
 
 func (nopCloser) Close() {}
@@ -102,26 +144,39 @@ func NewClient(provider string) IAI {
 type AIConfiguration struct {
 	Providers       []AIProvider `mapstructure:"providers"`
 	DefaultProvider string       `mapstructure:"defaultprovider"`
+	// Fallbacks are the providers asked, in order, when the selected
+	// provider fails.
+	Fallbacks []string `mapstructure:"fallbacks" yaml:"fallbacks,omitempty"`
+	// MaxTokensPerDay limits the tokens used to explain results per day.
+	MaxTokensPerDay int `mapstructure:"maxtokensperday" yaml:"maxtokensperday,omitempty"`
 }
 
 type AIProvider struct {
-	Name           string        `mapstructure:"name"`
-	Model          string        `mapstructure:"model"`
-	Password       string        `mapstructure:"password" yaml:"password,omitempty"`
-	BaseURL        string        `mapstructure:"baseurl" yaml:"baseurl,omitempty"`
-	ProxyEndpoint  string        `mapstructure:"proxyEndpoint" yaml:"proxyEndpoint,omitempty"`
-	ProxyPort      string        `mapstructure:"proxyPort" yaml:"proxyPort,omitempty"`
-	EndpointName   string        `mapstructure:"endpointname" yaml:"endpointname,omitempty"`
-	Engine         string        `mapstructure:"engine" yaml:"engine,omitempty"`
-	Temperature    float32       `mapstructure:"temperature" yaml:"temperature,omitempty"`
-	ProviderRegion string        `mapstructure:"providerregion" yaml:"providerregion,omitempty"`
-	ProviderId     string        `mapstructure:"providerid" yaml:"providerid,omitempty"`
-	CompartmentId  string        `mapstructure:"compartmentid" yaml:"compartmentid,omitempty"`
-	TopP           float32       `mapstructure:"topp" yaml:"topp,omitempty"`
-	TopK           int32         `mapstructure:"topk" yaml:"topk,omitempty"`
-	MaxTokens      int           `mapstructure:"maxtokens" yaml:"maxtokens,omitempty"`
-	OrganizationId string        `mapstructure:"organizationid" yaml:"organizationid,omitempty"`
-	CustomHeaders  []http.Header `mapstructure:"customHeaders"`
+	Name              string        `mapstructure:"name"`
+	Model             string        `mapstructure:"model"`
+	Password          string        `mapstructure:"password" yaml:"password,omitempty"`
+	BaseURL           string        `mapstructure:"baseurl" yaml:"baseurl,omitempty"`
+	ProxyEndpoint     string        `mapstructure:"proxyEndpoint" yaml:"proxyEndpoint,omitempty"`
+	ProxyPort         string        `mapstructure:"proxyPort" yaml:"proxyPort,omitempty"`
+	EndpointName      string        `mapstructure:"endpointname" yaml:"endpointname,omitempty"`
+	Engine            string        `mapstructure:"engine" yaml:"engine,omitempty"`
+	Temperature       float32       `mapstructure:"temperature" yaml:"temperature,omitempty"`
+	ProviderRegion    string        `mapstructure:"providerregion" yaml:"providerregion,omitempty"`
+	ProviderId        string        `mapstructure:"providerid" yaml:"providerid,omitempty"`
+	CompartmentId     string        `mapstructure:"compartmentid" yaml:"compartmentid,omitempty"`
+	TopP              float32       `mapstructure:"topp" yaml:"topp,omitempty"`
+	TopK              int32         `mapstructure:"topk" yaml:"topk,omitempty"`
+	MaxTokens         int           `mapstructure:"maxtokens" yaml:"maxtokens,omitempty"`
+	OrganizationId    string        `mapstructure:"organizationid" yaml:"organizationid,omitempty"`
+	CustomHeaders     []http.Header `mapstructure:"customHeaders"`
+	RequestsPerMinute float64       `mapstructure:"requestsperminute" yaml:"requestsperminute,omitempty"`
+	InputCost         float64       `mapstructure:"inputcost" yaml:"inputcost,omitempty"`
+	OutputCost        float64       `mapstructure:"outputcost" yaml:"outputcost,omitempty"`
+	ContextWindow     int           `mapstructure:"contextwindow" yaml:"contextwindow,omitempty"`
+	// HTTP configures the http backend.
+	HTTP *HTTPBackendConfig `mapstructure:"http" yaml:"http,omitempty"`
+	// KB are the user-defined entries of the kb backend.
+	KB []KBEntry `mapstructure:"kb" yaml:"kb,omitempty"`
 }
 
 func (p *AIProvider) GetBaseURL() string {
@@ -183,7 +238,19 @@ func (p *AIProvider) GetCustomHeaders() []http.Header {
 	return p.CustomHeaders
 }
 
-var passwordlessProviders = []string{"localai", "ollama", "amazonsagemaker", "amazonbedrock", "googlevertexai", "oci", "customrest"}
+func (p *AIProvider) GetHTTPBackend() *HTTPBackendConfig {
+	return p.HTTP
+}
+
+func (p *AIProvider) GetKBEntries() []KBEntry {
+	return p.KB
+}
+
+func (p *AIProvider) GetPricing() Pricing {
+	return Pricing{InputCost: p.InputCost, OutputCost: p.OutputCost}
+}
+
+var passwordlessProviders = []string{"localai", "ollama", "amazonsagemaker", "amazonbedrock", "googlevertexai", "oci", "customrest", "replay", "http", "kb"}
 
 func NeedPassword(backend string) bool {
 	for _, b := range passwordlessProviders {
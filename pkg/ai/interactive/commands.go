@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interactive
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const commandsHelp = `/save [name]   save the session, under its name or a new one
+/load [name]   resume a saved session, or list them
+/reset         forget the conversation and the focused result
+/result [N]    focus the questions on result N, or list the results
+exit           close interactive mode`
+
+// command runs an interactive command, e.g. "/save name", and returns what to
+// print.
+func (a *InteractionRunner) command(line string) (string, error) {
+	fields := strings.Fields(line)
+	name, args := fields[0], fields[1:]
+	if len(args) > 1 {
+		return "", fmt.Errorf("%s takes at most one argument", name)
+	}
+	arg := ""
+	if len(args) == 1 {
+		arg = args[0]
+	}
+	switch name {
+	case "/help":
+		return commandsHelp, nil
+	case "/save":
+		return a.save(arg)
+	case "/load":
+		return a.load(arg)
+	case "/reset":
+		a.session.reset()
+		return "Conversation reset", nil
+	case "/result":
+		return a.focus(arg)
+	}
+	return "", fmt.Errorf("unknown command %s, see /help", name)
+}
+
+func (a *InteractionRunner) save(name string) (string, error) {
+	if name == "" {
+		name = a.sessionName
+	}
+	if name == "" {
+		name = time.Now().Format("20060102-150405")
+	}
+	if err := SaveSession(a.sessionDir, name, a.session); err != nil {
+		return "", err
+	}
+	a.sessionName = name
+	return fmt.Sprintf("Session saved as %s", name), nil
+}
+
+func (a *InteractionRunner) load(name string) (string, error) {
+	if name == "" {
+		names, err := ListSessions(a.sessionDir)
+		if err != nil {
+			return "", err
+		}
+		if len(names) == 0 {
+			return "No saved sessions", nil
+		}
+		return "Saved sessions:\n" + strings.Join(names, "\n"), nil
+	}
+	session, err := LoadSession(a.sessionDir, name)
+	if err != nil {
+		return "", err
+	}
+	a.session = session
+	a.sessionName = name
+	return fmt.Sprintf("Resumed session %s saved on %s, questions asked: %d", name,
+		session.Saved.Format(time.DateTime), len(session.History)), nil
+}
+
+func (a *InteractionRunner) focus(arg string) (string, error) {
+	results := a.config.Results
+	if arg == "" {
+		if len(results) == 0 {
+			return "No results", nil
+		}
+		var list strings.Builder
+		for n, result := range results {
+			fmt.Fprintf(&list, "%d: %s %s\n", n, result.Kind, result.Name)
+		}
+		return strings.TrimSuffix(list.String(), "\n"), nil
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 0 || n >= len(results) {
+		return "", fmt.Errorf("no result %s, see /result", arg)
+	}
+	result := results[n]
+	a.session.Result = &result
+	return fmt.Sprintf("Questions now focus on result %d: %s %s, /reset to ask about all of them", n, result.Kind, result.Name), nil
+}
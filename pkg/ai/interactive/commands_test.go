@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interactive
+
+import (
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands(t *testing.T) {
+	runner := &InteractionRunner{
+		config: &analysis.Analysis{Results: []common.Result{
+			{Kind: "Pod", Name: "default/web"},
+			{Kind: "Service", Name: "default/api"},
+		}},
+		session:    &Session{Context: "context"},
+		sessionDir: t.TempDir(),
+	}
+
+	output, err := runner.command("/result")
+	require.NoError(t, err)
+	require.Equal(t, "0: Pod default/web\n1: Service default/api", output)
+	_, err = runner.command("/result 2")
+	require.EqualError(t, err, "no result 2, see /result")
+	_, err = runner.command("/result 1")
+	require.NoError(t, err)
+	require.Equal(t, "Service", runner.session.Result.Kind)
+
+	runner.session.History = []Turn{{Question: "question", Answer: "answer"}}
+	output, err = runner.command("/save investigation")
+	require.NoError(t, err)
+	require.Equal(t, "Session saved as investigation", output)
+
+	_, err = runner.command("/reset")
+	require.NoError(t, err)
+	require.Nil(t, runner.session.Result)
+	require.Empty(t, runner.session.History)
+
+	output, err = runner.command("/load")
+	require.NoError(t, err)
+	require.Equal(t, "Saved sessions:\ninvestigation", output)
+	output, err = runner.command("/load investigation")
+	require.NoError(t, err)
+	require.Contains(t, output, "questions asked: 1")
+	require.Equal(t, "Service", runner.session.Result.Kind)
+	require.Equal(t, []Turn{{Question: "question", Answer: "answer"}}, runner.session.History)
+
+	_, err = runner.command("/quit")
+	require.EqualError(t, err, "unknown command /quit, see /help")
+	_, err = runner.command("/save a b")
+	require.EqualError(t, err, "/save takes at most one argument")
+}
@@ -1,18 +1,29 @@
 package interactive
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
 	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
 	"github.com/pterm/pterm"
+	"github.com/spf13/viper"
 )
 
 type INTERACTIVE_STATE int
 
 const (
 	prompt = "Given the following context: "
+
+	agentPrompt = `You are a Kubernetes troubleshooting assistant answering questions about the problems of a cluster.
+You can call read-only tools to inspect the cluster, call them when the context is not enough to answer.`
+
+	// maxToolRounds bounds the tool calls made to answer a question.
+	maxToolRounds = 10
 )
 
 const (
@@ -21,22 +32,48 @@ const (
 )
 
 type InteractionRunner struct {
-	config        *analysis.Analysis
-	State         chan INTERACTIVE_STATE
-	contextWindow []byte
+	config *analysis.Analysis
+	State  chan INTERACTIVE_STATE
+	// session is the conversation so far, saved and loaded from sessionDir.
+	session     *Session
+	sessionName string
+	sessionDir  string
+	// tools are the tools the model may call, nil if it can't call any.
+	tools *toolset
 }
 
-func NewInteractionRunner(config *analysis.Analysis, contextWindow []byte) *InteractionRunner {
-	return &InteractionRunner{
-		config:        config,
-		contextWindow: contextWindow,
-		State:         make(chan INTERACTIVE_STATE),
+// NewInteractionRunner starts a conversation about the analysis. The output
+// of the cluster tools is not anonymized, so with anonymize they are only
+// enabled if interactive.tools allows them explicitly.
+func NewInteractionRunner(config *analysis.Analysis, contextWindow []byte, anonymize bool) *InteractionRunner {
+	runner := &InteractionRunner{
+		config:     config,
+		State:      make(chan INTERACTIVE_STATE),
+		session:    &Session{Context: string(contextWindow)},
+		sessionDir: DefaultSessionDir(),
 	}
+	if _, ok := ai.ToolCalling(config.AIClient); !ok || config.Client == nil {
+		return runner
+	}
+	if anonymize {
+		if !viper.IsSet("interactive.tools") {
+			color.Yellow("Warning: cluster tools disabled with --anonymize, their output is not anonymized. Allow them with interactive.tools to enable them anyway.")
+			return runner
+		}
+		color.Yellow("Warning: the output of the cluster tools is sent to the AI provider without being anonymized.")
+	}
+	tools, err := newToolset(config.Client, viper.GetStringSlice("interactive.tools"))
+	if err != nil {
+		color.Yellow("Warning: cluster tools disabled: %v", err)
+	} else {
+		runner.tools = tools
+	}
+	return runner
 }
 
 func (a *InteractionRunner) StartInteraction() {
 	a.State <- E_RUNNING
-	pterm.Println("Interactive mode enabled [type exit to close.]")
+	pterm.Println("Interactive mode enabled [type exit to close, /help for the commands.]")
 	for {
 
 		query := pterm.DefaultInteractiveTextInput.WithMultiLine(false)
@@ -44,24 +81,86 @@ func (a *InteractionRunner) StartInteraction() {
 		if err != nil {
 			fmt.Println(err)
 		}
+		queryString = strings.TrimSpace(queryString)
 		if queryString == "" {
 			continue
 		}
-		if strings.Contains(queryString, "exit") {
+		if queryString == "exit" || queryString == "/exit" {
 			a.State <- E_EXITED
 			continue
 		}
 		pterm.Println()
-		contextWindow := fmt.Sprintf("%s %s %s", prompt, string(a.contextWindow),
-			queryString)
+		if strings.HasPrefix(queryString, "/") {
+			output, err := a.command(queryString)
+			if err != nil {
+				color.Red("Error: %v", err)
+				continue
+			}
+			pterm.Println(output)
+			continue
+		}
 
-		response, err := a.config.AIClient.GetCompletion(a.config.Context,
-			contextWindow)
-		if err != nil {
+		if err := a.ask(queryString); err != nil {
 			color.Red("Error: %v", err)
 			a.State <- E_EXITED
 			continue
 		}
-		pterm.Println(response)
+		pterm.Println()
+	}
+}
+
+// ask answers a question given the conversation so far, and adds both to the
+// conversation.
+func (a *InteractionRunner) ask(question string) error {
+	ctx := a.context()
+	if err := a.session.trim(ctx, a.config.AIClient, a.config.ContextWindow(), question); err != nil {
+		color.Yellow("Warning: earlier questions forgotten: %v", err)
+	}
+
+	var answer string
+	var err error
+	if client, ok := ai.ToolCalling(a.config.AIClient); ok && a.tools != nil {
+		answer, err = a.runAgent(client, a.session.messages(question))
+		pterm.Print(answer)
+	} else {
+		// Print the response as it is generated.
+		answer, err = ai.StreamCompletion(ctx, a.config.AIClient, a.session.prompt(question), os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	a.session.History = append(a.session.History, Turn{Question: question, Answer: answer})
+	return nil
+}
+
+func (a *InteractionRunner) context() context.Context {
+	if a.config.Context == nil {
+		return context.Background()
+	}
+	return a.config.Context
+}
+
+// runAgent answers the last question of a conversation, letting the model call
+// the cluster tools. Each tool call is shown before it runs.
+func (a *InteractionRunner) runAgent(client ai.IToolCallingAI, messages []ai.ChatMessage) (string, error) {
+	ctx := a.context()
+	for i := 0; i < maxToolRounds; i++ {
+		answer, err := client.Chat(ctx, messages, a.tools.definitions())
+		if err != nil {
+			return "", err
+		}
+		messages = append(messages, answer)
+		if len(answer.ToolCalls) == 0 {
+			return answer.Content, nil
+		}
+		for _, call := range answer.ToolCalls {
+			color.Cyan("Calling %s %s", call.Name, call.Arguments)
+			messages = append(messages, ai.ChatMessage{
+				Role:       ai.RoleTool,
+				Content:    a.tools.run(ctx, call),
+				ToolCallID: call.ID,
+			})
+		}
 	}
+	return "", errors.New("too many tool calls to answer the question")
 }
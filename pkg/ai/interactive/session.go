@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interactive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/viper"
+)
+
+// Session is an interactive investigation: the analysis it is about and the
+// conversation so far. It is saved to resume the investigation later.
+type Session struct {
+	// Context is the output of the analysis.
+	Context string `json:"context"`
+	// Result is the finding the questions focus on, if any.
+	Result *common.Result `json:"result,omitempty"`
+	// Summary summarizes the turns trimmed from History.
+	Summary string    `json:"summary,omitempty"`
+	History []Turn    `json:"history,omitempty"`
+	Saved   time.Time `json:"saved,omitempty"`
+}
+
+// Turn is a question and its answer.
+type Turn struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// context returns what the questions are about.
+func (s *Session) context() string {
+	if s.Result == nil {
+		return s.Context
+	}
+	data, err := json.MarshalIndent(s.Result, "", "  ")
+	if err != nil {
+		return s.Context
+	}
+	return string(data)
+}
+
+// conversation writes the summary and the history of the session.
+func (s *Session) conversation() string {
+	var conversation strings.Builder
+	if s.Summary != "" {
+		fmt.Fprintf(&conversation, "\nSummary of the earlier conversation: %s", s.Summary)
+	}
+	for _, turn := range s.History {
+		fmt.Fprintf(&conversation, "\nQuestion: %s\nAnswer: %s", turn.Question, turn.Answer)
+	}
+	return conversation.String()
+}
+
+// prompt returns the prompt asking a question, for the clients completing
+// text.
+func (s *Session) prompt(question string) string {
+	return fmt.Sprintf("%s %s%s\nQuestion: %s", prompt, s.context(), s.conversation(), question)
+}
+
+// messages returns the conversation asking a question, for the clients
+// calling tools.
+func (s *Session) messages(question string) []ai.ChatMessage {
+	context := prompt + " " + s.context()
+	if s.Summary != "" {
+		context += "\nSummary of the earlier conversation: " + s.Summary
+	}
+	messages := []ai.ChatMessage{
+		{Role: ai.RoleSystem, Content: agentPrompt},
+		{Role: ai.RoleUser, Content: context},
+	}
+	for _, turn := range s.History {
+		messages = append(messages,
+			ai.ChatMessage{Role: ai.RoleUser, Content: turn.Question},
+			ai.ChatMessage{Role: ai.RoleAssistant, Content: turn.Answer})
+	}
+	return append(messages, ai.ChatMessage{Role: ai.RoleUser, Content: question})
+}
+
+// trim keeps the prompt asking question within half of the context window,
+// leaving the other half to the answer. The oldest turns are trimmed first
+// and summarized, or dropped if they can't be.
+func (s *Session) trim(ctx context.Context, client ai.IAI, window int, question string) error {
+	history := s.History
+	for len(s.History) > 0 && ai.EstimateTokens(s.prompt(question)) > window/2 {
+		s.History = s.History[1:]
+	}
+	trimmed := history[:len(history)-len(s.History)]
+	if len(trimmed) == 0 {
+		return nil
+	}
+	dropped := &Session{History: trimmed}
+	completion, err := ai.Completion(ctx, client, fmt.Sprintf(ai.SummaryPrompt, s.Summary, dropped.conversation()), io.Discard)
+	if err != nil {
+		return fmt.Errorf("summarizing the conversation: %w", err)
+	}
+	s.Summary = strings.TrimSpace(completion.Text)
+	return nil
+}
+
+// reset forgets the conversation and the focus.
+func (s *Session) reset() {
+	s.Result = nil
+	s.Summary = ""
+	s.History = nil
+}
+
+// DefaultSessionDir returns the directory of the saved sessions, next to the
+// configuration file.
+func DefaultSessionDir() string {
+	if config := viper.ConfigFileUsed(); config != "" {
+		return filepath.Join(filepath.Dir(config), "sessions")
+	}
+	return filepath.Join(xdg.ConfigHome, "k8sgpt", "sessions")
+}
+
+func sessionFile(dir, name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid session name %q", name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// SaveSession saves a session under name in dir.
+func SaveSession(dir, name string, session *Session) error {
+	file, err := sessionFile(dir, name)
+	if err != nil {
+		return err
+	}
+	session.Saved = time.Now()
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	// The conversation may quote logs and objects of the cluster.
+	return os.WriteFile(file, data, 0600)
+}
+
+// LoadSession loads the session saved under name in dir.
+func LoadSession(dir, name string) (*Session, error) {
+	file, err := sessionFile(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no session %s", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("invalid session %s: %w", name, err)
+	}
+	return session, nil
+}
+
+// ListSessions returns the names of the sessions saved in dir.
+func ListSessions(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(file), ".json"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
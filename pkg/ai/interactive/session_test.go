@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interactive
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionPrompt(t *testing.T) {
+	session := &Session{
+		Context: "0: Pod default/web\n- Error: Back-off pulling image",
+		Summary: "The image tag does not exist.",
+		History: []Turn{{Question: "Which image?", Answer: "nginx:1.99"}},
+	}
+	require.Equal(t, prompt+" 0: Pod default/web\n- Error: Back-off pulling image"+
+		"\nSummary of the earlier conversation: The image tag does not exist."+
+		"\nQuestion: Which image?\nAnswer: nginx:1.99"+
+		"\nQuestion: How do I fix it?", session.prompt("How do I fix it?"))
+
+	messages := session.messages("How do I fix it?")
+	require.Len(t, messages, 5)
+	require.Equal(t, ai.RoleSystem, messages[0].Role)
+	require.Contains(t, messages[1].Content, "Summary of the earlier conversation: The image tag does not exist.")
+	require.Equal(t, ai.ChatMessage{Role: ai.RoleAssistant, Content: "nginx:1.99"}, messages[3])
+	require.Equal(t, ai.ChatMessage{Role: ai.RoleUser, Content: "How do I fix it?"}, messages[4])
+
+	session.Result = &common.Result{Kind: "Pod", Name: "default/web"}
+	require.NotContains(t, session.prompt("Why?"), "Back-off pulling image")
+	require.Contains(t, session.prompt("Why?"), `"name": "default/web"`)
+
+	session.reset()
+	require.Equal(t, prompt+" 0: Pod default/web\n- Error: Back-off pulling image\nQuestion: Why?", session.prompt("Why?"))
+}
+
+func TestSessionTrim(t *testing.T) {
+	session := &Session{Context: "context"}
+	for i := 0; i < 10; i++ {
+		session.History = append(session.History, Turn{Question: "question", Answer: strings.Repeat("answer ", 20)})
+	}
+
+	// The conversation fits the window.
+	require.NoError(t, session.trim(context.Background(), &ai.NoOpAIClient{}, 2000, "question"))
+	require.Len(t, session.History, 10)
+	require.Empty(t, session.Summary)
+
+	window := 2 * ai.EstimateTokens((&Session{Context: "context", History: session.History[7:]}).prompt("question"))
+	require.NoError(t, session.trim(context.Background(), &ai.NoOpAIClient{}, window, "question"))
+	require.Len(t, session.History, 3)
+	require.True(t, strings.HasPrefix(session.Summary, "I am a noop response to the prompt Summarize the following conversation"))
+	require.Equal(t, 7, strings.Count(session.Summary, "Question: question"))
+}
+
+func TestSaveLoadSession(t *testing.T) {
+	dir := t.TempDir()
+	names, err := ListSessions(dir)
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	session := &Session{
+		Context: "context",
+		Result:  &common.Result{Kind: "Pod", Name: "default/web"},
+		Summary: "summary",
+		History: []Turn{{Question: "question", Answer: "answer"}},
+	}
+	require.NoError(t, SaveSession(dir, "web", session))
+	require.NoError(t, SaveSession(dir, "api", &Session{Context: "context"}))
+
+	loaded, err := LoadSession(dir, "web")
+	require.NoError(t, err)
+	require.True(t, session.Saved.Equal(loaded.Saved))
+	loaded.Saved = session.Saved
+	require.Equal(t, session, loaded)
+
+	names, err = ListSessions(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"api", "web"}, names)
+
+	_, err = LoadSession(dir, "db")
+	require.EqualError(t, err, "no session db")
+	require.EqualError(t, SaveSession(dir, "../web", session), `invalid session name "../web"`)
+}
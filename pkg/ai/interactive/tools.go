@@ -0,0 +1,319 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interactive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// maxToolOutput is the number of bytes of a tool result given to the model.
+	maxToolOutput = 16000
+	// maxEvents is the number of most recent events listed.
+	maxEvents = 20
+	// defaultLogLines and maxLogLines bound the log lines tailed.
+	defaultLogLines = 50
+	maxLogLines     = 500
+	// maxOwners bounds the owner chain.
+	maxOwners = 10
+)
+
+// kindGroups are the API groups searched for a kind given without apiVersion.
+var kindGroups = []string{"", "apps", "batch", "networking.k8s.io", "autoscaling", "policy",
+	"gateway.networking.k8s.io", "admissionregistration.k8s.io", "storage.k8s.io", "rbac.authorization.k8s.io"}
+
+var objectParameters = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"kind":       map[string]interface{}{"type": "string", "description": "Kind of the object, e.g. Deployment"},
+		"apiVersion": map[string]interface{}{"type": "string", "description": "API version of the object, only needed for custom resources"},
+		"namespace":  map[string]interface{}{"type": "string", "description": "Namespace of the object, empty for cluster scoped objects"},
+		"name":       map[string]interface{}{"type": "string", "description": "Name of the object"},
+	},
+	"required": []string{"kind", "name"},
+}
+
+// Tools are the read-only operations the model may run in the cluster.
+var Tools = []ai.Tool{
+	{
+		Name:        "get_object_yaml",
+		Description: "Get the YAML of a Kubernetes object, without its managed fields. Secrets can't be read.",
+		Parameters:  objectParameters,
+	},
+	{
+		Name:        "list_events",
+		Description: "List the most recent events of a namespace, or of an object of the namespace if its name is given.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"namespace": map[string]interface{}{"type": "string", "description": "Namespace of the events"},
+				"name":      map[string]interface{}{"type": "string", "description": "Name of the object the events are about"},
+			},
+			"required": []string{"namespace"},
+		},
+	},
+	{
+		Name:        "tail_logs",
+		Description: "Get the last lines of the logs of a container of a pod.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"namespace": map[string]interface{}{"type": "string", "description": "Namespace of the pod"},
+				"pod":       map[string]interface{}{"type": "string", "description": "Name of the pod"},
+				"container": map[string]interface{}{"type": "string", "description": "Name of the container, if the pod has several"},
+				"lines":     map[string]interface{}{"type": "integer", "description": fmt.Sprintf("Number of lines, %d by default", defaultLogLines)},
+				"previous":  map[string]interface{}{"type": "boolean", "description": "Get the logs of the previous, crashed, container"},
+			},
+			"required": []string{"namespace", "pod"},
+		},
+	},
+	{
+		Name:        "describe_owner_chain",
+		Description: "Describe the chain of owners of a Kubernetes object, e.g. Pod, ReplicaSet, Deployment.",
+		Parameters:  objectParameters,
+	},
+}
+
+// toolArguments are the arguments of all the tools.
+type toolArguments struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	Pod        string `json:"pod"`
+	Container  string `json:"container"`
+	Lines      int64  `json:"lines"`
+	Previous   bool   `json:"previous"`
+}
+
+// toolset runs the allowed tools through a kubernetes client.
+type toolset struct {
+	client  *kubernetes.Client
+	allowed map[string]bool
+}
+
+// newToolset allows the given tools, or all of them if none is given.
+func newToolset(client *kubernetes.Client, allowlist []string) (*toolset, error) {
+	t := &toolset{client: client, allowed: map[string]bool{}}
+	for _, tool := range Tools {
+		t.allowed[tool.Name] = len(allowlist) == 0
+	}
+	for _, name := range allowlist {
+		if _, ok := t.allowed[name]; !ok {
+			return nil, fmt.Errorf("unknown interactive tool %q", name)
+		}
+		t.allowed[name] = true
+	}
+	return t, nil
+}
+
+// definitions returns the allowed tools.
+func (t *toolset) definitions() []ai.Tool {
+	var tools []ai.Tool
+	for _, tool := range Tools {
+		if t.allowed[tool.Name] {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// run runs a tool call, and returns its result, or its error, for the model.
+func (t *toolset) run(ctx context.Context, call ai.ToolCall) string {
+	result, err := t.call(ctx, call)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	if len(result) > maxToolOutput {
+		result = result[:maxToolOutput] + "\n[truncated]"
+	}
+	return result
+}
+
+func (t *toolset) call(ctx context.Context, call ai.ToolCall) (string, error) {
+	if !t.allowed[call.Name] {
+		return "", fmt.Errorf("tool %s is not allowed", call.Name)
+	}
+	var args toolArguments
+	if call.Arguments != "" {
+		if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	switch call.Name {
+	case "get_object_yaml":
+		return t.objectYAML(ctx, args)
+	case "list_events":
+		return t.events(ctx, args)
+	case "tail_logs":
+		return t.logs(ctx, args)
+	case "describe_owner_chain":
+		return t.ownerChain(ctx, args)
+	}
+	return "", fmt.Errorf("unknown tool %s", call.Name)
+}
+
+// object fetches an object, resolving its kind with the REST mapper.
+func (t *toolset) object(ctx context.Context, apiVersion, kind, namespace, name string) (*unstructured.Unstructured, error) {
+	if kind == "" || name == "" {
+		return nil, fmt.Errorf("kind and name are required")
+	}
+	if strings.EqualFold(kind, "Secret") {
+		return nil, fmt.Errorf("reading secrets is not allowed")
+	}
+	var gvk schema.GroupVersionKind
+	if apiVersion != "" {
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return nil, err
+		}
+		gvk = gv.WithKind(kind)
+	} else {
+		mapper := t.client.CtrlClient.RESTMapper()
+		for _, group := range kindGroups {
+			if mapping, err := mapper.RESTMapping(schema.GroupKind{Group: group, Kind: kind}); err == nil {
+				gvk = mapping.GroupVersionKind
+				break
+			}
+		}
+		if gvk.Empty() {
+			return nil, fmt.Errorf("unknown kind %s, give its apiVersion", kind)
+		}
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := t.client.CtrlClient.Get(ctx, ctrl.ObjectKey{Namespace: namespace, Name: name}, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (t *toolset) objectYAML(ctx context.Context, args toolArguments) (string, error) {
+	obj, err := t.object(ctx, args.APIVersion, args.Kind, args.Namespace, args.Name)
+	if err != nil {
+		return "", err
+	}
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (t *toolset) events(ctx context.Context, args toolArguments) (string, error) {
+	options := metav1.ListOptions{}
+	if args.Name != "" {
+		options.FieldSelector = "involvedObject.name=" + args.Name
+	}
+	list, err := t.client.Client.CoreV1().Events(args.Namespace).List(ctx, options)
+	if err != nil {
+		return "", err
+	}
+	items := list.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].LastTimestamp.Before(&items[j].LastTimestamp)
+	})
+	if len(items) > maxEvents {
+		items = items[len(items)-maxEvents:]
+	}
+	if len(items) == 0 {
+		return "no events", nil
+	}
+	var events strings.Builder
+	for _, event := range items {
+		fmt.Fprintf(&events, "%s %s %s %s/%s: %s\n", event.LastTimestamp.UTC().Format("2006-01-02T15:04:05Z"),
+			event.Type, event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Message)
+	}
+	return events.String(), nil
+}
+
+func (t *toolset) logs(ctx context.Context, args toolArguments) (string, error) {
+	if args.Pod == "" {
+		return "", fmt.Errorf("pod is required")
+	}
+	lines := args.Lines
+	if lines <= 0 {
+		lines = defaultLogLines
+	}
+	lines = min(lines, maxLogLines)
+	logs, err := t.client.Client.CoreV1().Pods(args.Namespace).GetLogs(args.Pod, &v1.PodLogOptions{
+		Container: args.Container,
+		TailLines: &lines,
+		Previous:  args.Previous,
+	}).DoRaw(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(logs) == 0 {
+		return "no logs", nil
+	}
+	// Keep the most recent lines.
+	if len(logs) > maxToolOutput {
+		logs = logs[len(logs)-maxToolOutput:]
+	}
+	return string(logs), nil
+}
+
+func (t *toolset) ownerChain(ctx context.Context, args toolArguments) (string, error) {
+	obj, err := t.object(ctx, args.APIVersion, args.Kind, args.Namespace, args.Name)
+	if err != nil {
+		return "", err
+	}
+	var chain strings.Builder
+	fmt.Fprintf(&chain, "%s %s\n", obj.GetKind(), objectName(obj))
+	for i := 0; i < maxOwners; i++ {
+		owners := obj.GetOwnerReferences()
+		if len(owners) == 0 {
+			break
+		}
+		owner := owners[0]
+		if controller := metav1.GetControllerOfNoCopy(obj); controller != nil {
+			owner = *controller
+		}
+		fmt.Fprintf(&chain, "owned by %s %s\n", owner.Kind, owner.Name)
+		// Owners are in the same namespace, or cluster scoped.
+		owned := obj
+		obj, err = t.object(ctx, owner.APIVersion, owner.Kind, owned.GetNamespace(), owner.Name)
+		if apierrors.IsNotFound(err) && owned.GetNamespace() != "" {
+			obj, err = t.object(ctx, owner.APIVersion, owner.Kind, "", owner.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(&chain, "  (%v)\n", err)
+			break
+		}
+	}
+	return chain.String(), nil
+}
+
+func objectName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
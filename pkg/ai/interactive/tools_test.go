@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package interactive
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analysis"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testToolset(t *testing.T, allowlist []string) *toolset {
+	controller := true
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d4f", Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", Controller: &controller}},
+	}}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "web-5d4f-x2x", Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-5d4f", Controller: &controller}},
+		ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+	}}
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "default"}}
+	event := &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "web-5d4f-x2x.1", Namespace: "default"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "web-5d4f-x2x"},
+		Type:           "Warning",
+		Reason:         "BackOff",
+		Message:        "Back-off pulling image",
+	}
+
+	tools, err := newToolset(&kubernetes.Client{
+		Client: fake.NewSimpleClientset(pod, event),
+		CtrlClient: ctrlfake.NewClientBuilder().WithScheme(scheme.Scheme).
+			WithRESTMapper(testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)).
+			WithObjects(deployment, replicaSet, pod, secret).Build(),
+	}, allowlist)
+	require.NoError(t, err)
+	return tools
+}
+
+func TestToolsetRun(t *testing.T) {
+	tools := testToolset(t, nil)
+	require.Len(t, tools.definitions(), len(Tools))
+
+	tests := []struct {
+		name     string
+		call     ai.ToolCall
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "object yaml",
+			call:     ai.ToolCall{Name: "get_object_yaml", Arguments: `{"kind": "Pod", "namespace": "default", "name": "web-5d4f-x2x"}`},
+			contains: []string{"name: web-5d4f-x2x", "kind: ReplicaSet"},
+			excludes: []string{"managedFields"},
+		},
+		{
+			name:     "custom resource without apiVersion",
+			call:     ai.ToolCall{Name: "get_object_yaml", Arguments: `{"kind": "Widget", "name": "w"}`},
+			contains: []string{"error: unknown kind Widget"},
+		},
+		{
+			name:     "secret",
+			call:     ai.ToolCall{Name: "get_object_yaml", Arguments: `{"kind": "Secret", "namespace": "default", "name": "token"}`},
+			contains: []string{"error: reading secrets is not allowed"},
+		},
+		{
+			name:     "events",
+			call:     ai.ToolCall{Name: "list_events", Arguments: `{"namespace": "default"}`},
+			contains: []string{"Warning BackOff Pod/web-5d4f-x2x: Back-off pulling image"},
+		},
+		{
+			name:     "logs",
+			call:     ai.ToolCall{Name: "tail_logs", Arguments: `{"namespace": "default", "pod": "web-5d4f-x2x"}`},
+			contains: []string{"fake logs"},
+		},
+		{
+			name:     "owner chain",
+			call:     ai.ToolCall{Name: "describe_owner_chain", Arguments: `{"kind": "Pod", "namespace": "default", "name": "web-5d4f-x2x"}`},
+			contains: []string{"Pod default/web-5d4f-x2x\nowned by ReplicaSet web-5d4f\nowned by Deployment web\n"},
+		},
+		{
+			name:     "invalid arguments",
+			call:     ai.ToolCall{Name: "list_events", Arguments: `{`},
+			contains: []string{"error: invalid arguments"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tools.run(context.Background(), tt.call)
+			for _, s := range tt.contains {
+				require.Contains(t, result, s)
+			}
+			for _, s := range tt.excludes {
+				require.NotContains(t, result, s)
+			}
+		})
+	}
+}
+
+func TestToolsetAllowlist(t *testing.T) {
+	tools := testToolset(t, []string{"list_events"})
+	require.Equal(t, []ai.Tool{Tools[1]}, tools.definitions())
+	require.Equal(t, "error: tool tail_logs is not allowed",
+		tools.run(context.Background(), ai.ToolCall{Name: "tail_logs", Arguments: `{"namespace": "default", "pod": "web"}`}))
+
+	_, err := newToolset(&kubernetes.Client{}, []string{"exec"})
+	require.EqualError(t, err, `unknown interactive tool "exec"`)
+}
+
+func TestNewInteractionRunner_Anonymize(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	config := &analysis.Analysis{AIClient: &ai.OpenAIClient{}, Client: &kubernetes.Client{}}
+	require.NotNil(t, NewInteractionRunner(config, nil, false).tools)
+	// The output of the tools is not anonymized.
+	require.Nil(t, NewInteractionRunner(config, nil, true).tools)
+
+	viper.Set("interactive.tools", []string{"list_events"})
+	runner := NewInteractionRunner(config, nil, true)
+	require.NotNil(t, runner.tools)
+	require.Equal(t, []ai.Tool{Tools[1]}, runner.tools.definitions())
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const kbClientName = "kb"
+
+// kbCatalog is the built-in catalog of known failure signatures.
+//
+//go:embed kb.yaml
+var kbCatalog []byte
+
+// ErrNoKBEntry is returned by the kb backend for the failures it doesn't know.
+var ErrNoKBEntry = errors.New("no known failure signature matches")
+
+// promptFailures matches the failures of the built-in prompts, delimited by
+// triple dashes.
+var promptFailures = regexp.MustCompile(`(?s)--- (.*?) ---`)
+
+type failuresKey struct{}
+
+// WithFailures attaches the failures a prompt asks about to ctx, for the kb
+// backend to match them rather than the whole prompt.
+func WithFailures(ctx context.Context, failures string) context.Context {
+	return context.WithValue(ctx, failuresKey{}, failures)
+}
+
+// failures returns the failures a prompt asks about: the ones attached to
+// ctx, else the text between the triple dashes of the built-in prompts.
+func failures(ctx context.Context, prompt string) string {
+	if failures, ok := ctx.Value(failuresKey{}).(string); ok && failures != "" {
+		return failures
+	}
+	var blocks []string
+	for _, match := range promptFailures.FindAllStringSubmatch(prompt, -1) {
+		blocks = append(blocks, match[1])
+	}
+	return strings.Join(blocks, "\n")
+}
+
+// kbVariable is a variable of an explanation, set from a named group of the
+// pattern that matched.
+var kbVariable = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// KBEntry is a known failure signature: the patterns recognizing it in the
+// failures and its explanation. User-defined entries are listed in the `kb`
+// section of the provider.
+type KBEntry struct {
+	Name string `mapstructure:"name" json:"name" yaml:"name"`
+	// Patterns are regular expressions, the named groups of the one matching
+	// the failures can be used in the explanation as ${name}.
+	Patterns  []string `mapstructure:"patterns" json:"patterns" yaml:"patterns"`
+	Summary   string   `mapstructure:"summary" json:"summary" yaml:"summary"`
+	RootCause string   `mapstructure:"rootcause" json:"rootcause,omitempty" yaml:"rootcause,omitempty"`
+	Steps     []string `mapstructure:"steps" json:"steps,omitempty" yaml:"steps,omitempty"`
+	Commands  []string `mapstructure:"commands" json:"commands,omitempty" yaml:"commands,omitempty"`
+}
+
+// BuiltinKBEntries returns the entries of the built-in catalog.
+func BuiltinKBEntries() ([]KBEntry, error) {
+	var entries []KBEntry
+	if err := yaml.Unmarshal(kbCatalog, &entries); err != nil {
+		return nil, fmt.Errorf("invalid kb catalog: %w", err)
+	}
+	return entries, nil
+}
+
+// kbConfigurer is implemented by the configurations of the kb backend.
+type kbConfigurer interface {
+	GetKBEntries() []KBEntry
+}
+
+type kbEntry struct {
+	KBEntry
+	patterns []*regexp.Regexp
+}
+
+// KBClient explains failures offline, without any LLM, by matching them with
+// a catalog of known failure signatures. The user-defined entries are tried
+// before the built-in ones, and the first matching entry explains the
+// failures. Its explanations are in English.
+type KBClient struct {
+	nopCloser
+	entries []kbEntry
+}
+
+func (c *KBClient) Configure(config IAIConfig) error {
+	var entries []KBEntry
+	if configurer, ok := config.(kbConfigurer); ok {
+		entries = append(entries, configurer.GetKBEntries()...)
+	}
+	builtin, err := BuiltinKBEntries()
+	if err != nil {
+		return err
+	}
+	entries = append(entries, builtin...)
+
+	c.entries = nil
+	for _, entry := range entries {
+		if entry.Summary == "" || len(entry.Patterns) == 0 {
+			return fmt.Errorf("invalid kb entry %s: patterns and summary are required", entry.Name)
+		}
+		compiled := kbEntry{KBEntry: entry}
+		for _, pattern := range entry.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid kb entry %s: %w", entry.Name, err)
+			}
+			compiled.patterns = append(compiled.patterns, re)
+		}
+		c.entries = append(c.entries, compiled)
+	}
+	return nil
+}
+
+// kbExplanation is the structured explanation requested by StructuredPrompt.
+type kbExplanation struct {
+	Summary    string   `json:"summary"`
+	RootCause  string   `json:"rootCause,omitempty"`
+	Steps      []string `json:"steps,omitempty"`
+	Commands   []string `json:"commands,omitempty"`
+	Confidence string   `json:"confidence"`
+}
+
+func (c *KBClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	// Batches and remediations need an LLM: answering no ID explains the
+	// results of a batch one by one, and no patch is proposed.
+	if isPrompt(prompt, BatchPrompt) {
+		return "{}", nil
+	}
+	if isPrompt(prompt, RemediationPrompt) {
+		return `{"type": "none", "description": "The kb backend does not propose patches."}`, nil
+	}
+
+	// Only the failures are matched, not the rest of the prompt such as the
+	// events of a custom prompt.
+	explanation, ok := c.explain(failures(ctx, prompt))
+	if !ok {
+		return "", ErrNoKBEntry
+	}
+	if strings.Contains(prompt, StructuredPrompt) {
+		data, err := json.Marshal(explanation)
+		return string(data), err
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "Error: %s\n", explanation.Summary)
+	if explanation.RootCause != "" {
+		fmt.Fprintf(&text, "Root cause: %s\n", explanation.RootCause)
+	}
+	if len(explanation.Steps)+len(explanation.Commands) > 0 {
+		text.WriteString("Solution:\n")
+	}
+	for i, step := range explanation.Steps {
+		fmt.Fprintf(&text, "%d. %s\n", i+1, step)
+	}
+	for i, command := range explanation.Commands {
+		fmt.Fprintf(&text, "%d. `%s`\n", len(explanation.Steps)+i+1, command)
+	}
+	return text.String(), nil
+}
+
+// explain returns the explanation of the first entry matching the failures.
+func (c *KBClient) explain(failures string) (kbExplanation, bool) {
+	for _, entry := range c.entries {
+		for _, re := range entry.patterns {
+			match := re.FindStringSubmatch(failures)
+			if match == nil {
+				continue
+			}
+			expand := func(text string) string {
+				return kbVariable.ReplaceAllStringFunc(text, func(variable string) string {
+					name := kbVariable.FindStringSubmatch(variable)[1]
+					if index := re.SubexpIndex(name); index > 0 && match[index] != "" {
+						return match[index]
+					}
+					// Like the placeholders of the commands.
+					return "<" + name + ">"
+				})
+			}
+			explanation := kbExplanation{
+				Summary:    expand(entry.Summary),
+				RootCause:  expand(entry.RootCause),
+				Confidence: "medium",
+			}
+			for _, step := range entry.Steps {
+				explanation.Steps = append(explanation.Steps, expand(step))
+			}
+			for _, command := range entry.Commands {
+				explanation.Commands = append(explanation.Commands, expand(command))
+			}
+			return explanation, true
+		}
+	}
+	return kbExplanation{}, false
+}
+
+func (c *KBClient) GetName() string {
+	return kbClientName
+}
+
+// isPrompt reports whether prompt was built from format, by finding the text
+// between its variables in order.
+func isPrompt(prompt, format string) bool {
+	for _, part := range strings.Split(format, "%s") {
+		index := strings.Index(prompt, part)
+		if index < 0 {
+			return false
+		}
+		prompt = prompt[index+len(part):]
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKBClient_BuiltinCatalog(t *testing.T) {
+	client := &KBClient{}
+	require.NoError(t, client.Configure(&AIProvider{Name: "kb"}))
+
+	tests := []struct {
+		failure string
+		summary string
+	}{
+		{
+			failure: "the last termination reason is OOMKilled container=api pod=api-7d9f",
+			summary: "The container api was killed because it used more memory than its limit.",
+		},
+		{
+			failure: `Back-off pulling image "nginx:1.99"`,
+			summary: "Kubernetes can't pull the image nginx:1.99 of the container and is backing off.",
+		},
+		{
+			failure: `Failed to pull image "nginx:1.99": rpc error: code = NotFound desc = failed to pull and unpack image "docker.io/library/nginx:1.99": docker.io/library/nginx:1.99: not found`,
+			summary: "The image nginx:1.99 does not exist.",
+		},
+		{
+			failure: "back-off 5m0s restarting failed container=api pod=api-7d9f_default(0b5c)",
+			summary: "The container api keeps exiting right after it starts, so Kubernetes restarts it with an increasing delay.",
+		},
+		{
+			failure: "0/3 nodes are available: 3 node(s) had untolerated taint {dedicated: gpu}. preemption: 0/3 nodes are available: 3 Preemption is not helpful for scheduling.",
+			summary: "The pod can't be scheduled because the nodes have taints it does not tolerate.",
+		},
+		{
+			failure: "0/3 nodes are available: 3 Insufficient memory. preemption: 0/3 nodes are available: 3 No preemption victims found for incoming pod.",
+			summary: "The pod can't be scheduled because no node has enough memory left for its requests.",
+		},
+		{
+			failure: `storageclass.storage.k8s.io "fast" not found`,
+			summary: "The PersistentVolumeClaim is Pending because its StorageClass fast does not exist.",
+		},
+		{
+			failure: "no persistent volumes available for this claim and no storage class is set",
+			summary: "The PersistentVolumeClaim is Pending because no PersistentVolume matches it and it has no StorageClass to provision one.",
+		},
+		{
+			failure: "Ingress uses the ingress class nginx which does not exist.",
+			summary: "The Ingress uses the IngressClass nginx, which does not exist, so no controller serves it.",
+		},
+		{
+			failure: "Service has no endpoints, expected label app=web",
+			summary: "The Service has no endpoints because no running pod has the label app=web of its selector.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.failure, func(t *testing.T) {
+			completion, err := client.GetCompletion(context.Background(), fmt.Sprintf(PromptMap["default"], "english", tt.failure))
+			require.NoError(t, err)
+			require.Contains(t, completion, "Error: "+tt.summary+"\n")
+
+			var explanation struct {
+				Summary string
+			}
+			completion, err = client.GetCompletion(context.Background(), fmt.Sprintf(PromptMap["default"], "english", tt.failure)+"\n"+StructuredPrompt)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal([]byte(completion), &explanation))
+			require.Equal(t, tt.summary, explanation.Summary)
+		})
+	}
+}
+
+func TestKBClient_GetCompletion(t *testing.T) {
+	client := &KBClient{}
+	require.NoError(t, client.Configure(&AIProvider{Name: "kb", KB: []KBEntry{{
+		Name:     "registry-mirror",
+		Patterns: []string{`image "registry\.internal/(?P<image>[^"]+)"`},
+		Summary:  "The internal registry mirror has no ${image}.",
+		Steps:    []string{"Sync ${image} to the mirror."},
+		Commands: []string{"mirrorctl sync ${image} --tag ${tag}"},
+	}}}))
+
+	completion, err := client.GetCompletion(context.Background(), `--- Back-off pulling image "registry.internal/nginx:1.99" ---`)
+	require.NoError(t, err)
+	require.Equal(t, "Error: The internal registry mirror has no nginx:1.99.\n"+
+		"Solution:\n"+
+		"1. Sync nginx:1.99 to the mirror.\n"+
+		"2. `mirrorctl sync nginx:1.99 --tag <tag>`\n", completion)
+
+	completion, err = client.GetCompletion(context.Background(), `--- Back-off pulling image "nginx:1.99" ---`)
+	require.NoError(t, err)
+	require.Contains(t, completion, "Error: Kubernetes can't pull the image nginx:1.99")
+
+	_, err = client.GetCompletion(context.Background(), "--- Deployment has 1 replicas but 2 are available ---")
+	require.ErrorIs(t, err, ErrNoKBEntry)
+
+	// Only the failures are matched, not the events of a custom prompt.
+	prompt := "Why does the Deployment fail? Deployment has 1 replicas but 2 are available\nEvents: Back-off pulling image \"nginx:1.99\""
+	_, err = client.GetCompletion(WithFailures(context.Background(), "Deployment has 1 replicas but 2 are available"), prompt)
+	require.ErrorIs(t, err, ErrNoKBEntry)
+	_, err = client.GetCompletion(context.Background(), prompt)
+	require.ErrorIs(t, err, ErrNoKBEntry)
+
+	completion, err = client.GetCompletion(context.Background(), fmt.Sprintf(BatchPrompt, "english", `ID 1: --- Back-off pulling image "nginx:1.99" ---`, BatchAnswer))
+	require.NoError(t, err)
+	require.Equal(t, "{}", completion)
+	completion, err = client.GetCompletion(context.Background(), fmt.Sprintf(RemediationPrompt, "Pod", "", `Back-off pulling image "nginx:1.99"`, "", "english"))
+	require.NoError(t, err)
+	require.Contains(t, completion, `"type": "none"`)
+
+	require.EqualError(t, client.Configure(&AIProvider{KB: []KBEntry{{Name: "broken", Patterns: []string{"("}, Summary: "broken"}}}),
+		"invalid kb entry broken: error parsing regexp: missing closing ): `(`")
+	require.EqualError(t, client.Configure(&AIProvider{KB: []KBEntry{{Name: "empty", Patterns: []string{"x"}}}}),
+		"invalid kb entry empty: patterns and summary are required")
+}
@@ -16,8 +16,10 @@ package ai
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	ollama "github.com/ollama/ollama/api"
 )
@@ -49,19 +51,12 @@ func (c *OllamaClient) Configure(config IAIConfig) error {
 	}
 
 	proxyEndpoint := config.GetProxyEndpoint()
-	httpClient := http.DefaultClient
-	if proxyEndpoint != "" {
-		proxyUrl, err := url.Parse(proxyEndpoint)
-		if err != nil {
-			return err
-		}
-		transport := &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		}
-
-		httpClient = &http.Client{
-			Transport: transport,
-		}
+	transport, err := proxyTransport(proxyEndpoint)
+	if err != nil {
+		return err
+	}
+	httpClient := &http.Client{
+		Transport: NewRetryTransport(transport),
 	}
 
 	c.client = ollama.NewClient(baseClientURL, httpClient)
@@ -76,19 +71,24 @@ func (c *OllamaClient) Configure(config IAIConfig) error {
 	c.topP = config.GetTopP()
 	return nil
 }
-func (c *OllamaClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
-	req := &ollama.GenerateRequest{
+func (c *OllamaClient) generateRequest(prompt string, stream bool) *ollama.GenerateRequest {
+	return &ollama.GenerateRequest{
 		Model:  c.model,
 		Prompt: prompt,
-		Stream: new(bool),
+		Stream: &stream,
 		Options: map[string]interface{}{
 			"temperature": c.temperature,
 			"top_p":       c.topP,
 		},
 	}
+}
+
+func (c *OllamaClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	req := c.generateRequest(prompt, false)
 	completion := ""
 	respFunc := func(resp ollama.GenerateResponse) error {
 		completion = resp.Response
+		reportOllamaUsage(ctx, resp)
 		return nil
 	}
 	err := c.client.Generate(ctx, req, respFunc)
@@ -97,6 +97,28 @@ func (c *OllamaClient) GetCompletion(ctx context.Context, prompt string) (string
 	}
 	return completion, nil
 }
+
+func (c *OllamaClient) StreamCompletion(ctx context.Context, prompt string, w io.Writer) (string, error) {
+	var completion strings.Builder
+	respFunc := func(resp ollama.GenerateResponse) error {
+		completion.WriteString(resp.Response)
+		reportOllamaUsage(ctx, resp)
+		_, err := io.WriteString(w, resp.Response)
+		return err
+	}
+	if err := c.client.Generate(ctx, c.generateRequest(prompt, true), respFunc); err != nil {
+		return "", err
+	}
+	return completion.String(), nil
+}
+
+// reportOllamaUsage reports the usage sent in the last response.
+func reportOllamaUsage(ctx context.Context, resp ollama.GenerateResponse) {
+	if resp.Done {
+		reportUsage(ctx, Usage{PromptTokens: resp.PromptEvalCount, CompletionTokens: resp.EvalCount})
+	}
+}
+
 func (a *OllamaClient) GetName() string {
 	return ollamaClientName
 }
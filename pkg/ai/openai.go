@@ -16,8 +16,10 @@ package ai
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/sashabaranov/go-openai"
 )
@@ -31,6 +33,9 @@ type OpenAIClient struct {
 	model       string
 	temperature float32
 	topP        float32
+	// streamUsage requests the usage of streamed completions, which only
+	// the OpenAI API supports.
+	streamUsage bool
 	// organizationId string
 }
 
@@ -68,7 +73,7 @@ func (c *OpenAIClient) Configure(config IAIConfig) error {
 	customHeaders := config.GetCustomHeaders()
 	defaultConfig.HTTPClient = &http.Client{
 		Transport: &OpenAIHeaderTransport{
-			Origin:  transport,
+			Origin:  NewRetryTransport(transport),
 			Headers: customHeaders,
 		},
 	}
@@ -78,15 +83,15 @@ func (c *OpenAIClient) Configure(config IAIConfig) error {
 		return errors.New("error creating OpenAI client")
 	}
 	c.client = client
+	c.streamUsage = isOpenAIAPI(defaultConfig.BaseURL)
 	c.model = config.GetModel()
 	c.temperature = config.GetTemperature()
 	c.topP = config.GetTopP()
 	return nil
 }
 
-func (c *OpenAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
-	// Create a completion request
-	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+func (c *OpenAIClient) completionRequest(prompt string) openai.ChatCompletionRequest {
+	return openai.ChatCompletionRequest{
 		Model: c.model,
 		Messages: []openai.ChatCompletionMessage{
 			{
@@ -99,13 +104,75 @@ func (c *OpenAIClient) GetCompletion(ctx context.Context, prompt string) (string
 		PresencePenalty:  presencePenalty,
 		FrequencyPenalty: frequencyPenalty,
 		TopP:             c.topP,
-	})
+	}
+}
+
+func (c *OpenAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	// Create a completion request
+	resp, err := c.client.CreateChatCompletion(ctx, c.completionRequest(prompt))
 	if err != nil {
 		return "", err
 	}
+	reportOpenAIUsage(ctx, &resp.Usage)
 	return resp.Choices[0].Message.Content, nil
 }
 
+func (c *OpenAIClient) StreamCompletion(ctx context.Context, prompt string, w io.Writer) (string, error) {
+	req := c.completionRequest(prompt)
+	req.Stream = true
+	if c.streamUsage {
+		// The usage is sent in a last chunk without choices. OpenAI
+		// compatible servers may reject the option.
+		req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
+	stream, err := c.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+	return readChatCompletionStream(ctx, stream, w)
+}
+
+// isOpenAIAPI reports whether baseURL is the one of the OpenAI API, rather
+// than of an OpenAI compatible server.
+func isOpenAIAPI(baseURL string) bool {
+	u, err := url.Parse(baseURL)
+	return err == nil && u.Hostname() == "api.openai.com"
+}
+
+// readChatCompletionStream writes the deltas of an OpenAI compatible chat
+// completion stream to w, and returns the whole completion.
+func readChatCompletionStream(ctx context.Context, stream *openai.ChatCompletionStream, w io.Writer) (string, error) {
+	var completion strings.Builder
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return completion.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		reportOpenAIUsage(ctx, resp.Usage)
+		if len(resp.Choices) == 0 {
+			continue
+		}
+		delta := resp.Choices[0].Delta.Content
+		completion.WriteString(delta)
+		if _, err := io.WriteString(w, delta); err != nil {
+			return "", err
+		}
+	}
+}
+
+// reportOpenAIUsage reports the usage of an OpenAI compatible response, if
+// the backend sent it.
+func reportOpenAIUsage(ctx context.Context, usage *openai.Usage) {
+	if usage == nil || usage.TotalTokens == 0 {
+		return
+	}
+	reportUsage(ctx, Usage{PromptTokens: usage.PromptTokens, CompletionTokens: usage.CompletionTokens})
+}
+
 func (c *OpenAIClient) GetName() string {
 	return openAIClientName
 }
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// execPasswordTimeout bounds the commands of the exec: password references.
+const execPasswordTimeout = 30 * time.Second
+
+// PasswordRefSchemes are the prefixes of the password references, which are
+// resolved when the provider is configured instead of being stored in the
+// configuration file:
+//
+//	env:OPENAI_KEY               the environment variable OPENAI_KEY
+//	file:/var/run/secrets/key    the content of the file
+//	exec:command args            the output of the command, see execPassword
+//	k8s:namespace/secret/key     the key of a Secret of the cluster
+var PasswordRefSchemes = []string{"env", "file", "exec", "k8s"}
+
+// SecretGetter returns the value of a key of a Secret, to resolve the k8s:
+// password references.
+type SecretGetter func(ctx context.Context, namespace, name, key string) (string, error)
+
+// IsPasswordRef reports whether a password is a reference.
+func IsPasswordRef(password string) bool {
+	scheme, _, found := strings.Cut(password, ":")
+	if !found {
+		return false
+	}
+	for _, s := range PasswordRefSchemes {
+		if scheme == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePasswordRef checks the syntax of a password reference.
+func ValidatePasswordRef(ref string) error {
+	if !IsPasswordRef(ref) {
+		return fmt.Errorf("invalid password reference %q, expected one of %s:", ref, strings.Join(PasswordRefSchemes, ":, "))
+	}
+	scheme, value, _ := strings.Cut(ref, ":")
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("invalid password reference %q, missing the %s value", ref, scheme)
+	}
+	if scheme == "k8s" {
+		if _, _, _, err := secretKeyRef(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolvePassword returns the password a reference refers to, or the password
+// itself if it isn't a reference.
+func ResolvePassword(ctx context.Context, password string, secrets SecretGetter) (string, error) {
+	if !IsPasswordRef(password) {
+		return password, nil
+	}
+	if err := ValidatePasswordRef(password); err != nil {
+		return "", err
+	}
+	scheme, value, _ := strings.Cut(password, ":")
+	switch scheme {
+	case "env":
+		resolved := os.Getenv(value)
+		if resolved == "" {
+			return "", fmt.Errorf("environment variable %s is not set", value)
+		}
+		return resolved, nil
+	case "file":
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	case "exec":
+		return execPassword(ctx, value)
+	case "k8s":
+		namespace, name, key, _ := secretKeyRef(value)
+		if secrets == nil {
+			return "", errors.New("no cluster to read the secret from")
+		}
+		return secrets(ctx, namespace, name, key)
+	}
+	return "", fmt.Errorf("unknown password reference %s", scheme)
+}
+
+func secretKeyRef(value string) (string, string, string, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", fmt.Errorf("invalid secret reference %q, expected k8s:namespace/secret/key", value)
+	}
+	return parts[0], parts[1], parts[2], nil
+}
+
+// credentialOutput is the output of a credential helper: the JSON of a
+// docker credential helper, of a Kubernetes ExecCredential, or an object with
+// a password or a token.
+type credentialOutput struct {
+	Secret   string `json:"Secret"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+	Status   struct {
+		Token string `json:"token"`
+	} `json:"status"`
+}
+
+// execPassword runs a credential helper, without a shell, and returns the
+// password it prints, either in a JSON credentialOutput or alone.
+func execPassword(ctx context.Context, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, execPasswordTimeout)
+	defer cancel()
+	args := strings.Fields(command)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("running %s: %w", args[0], err)
+	}
+	text := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(text, "{") {
+		if text == "" {
+			return "", fmt.Errorf("%s printed no password", args[0])
+		}
+		return text, nil
+	}
+	var credential credentialOutput
+	if err := json.Unmarshal([]byte(text), &credential); err != nil {
+		return "", fmt.Errorf("invalid output of %s: %w", args[0], err)
+	}
+	for _, password := range []string{credential.Secret, credential.Password, credential.Token, credential.Status.Token} {
+		if password != "" {
+			return password, nil
+		}
+	}
+	return "", fmt.Errorf("%s printed no password", args[0])
+}
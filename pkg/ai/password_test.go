@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePassword(t *testing.T) {
+	t.Setenv("K8SGPT_TEST_KEY", "env-key")
+	file := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(file, []byte("file-key\n"), 0600))
+	secrets := func(_ context.Context, namespace, name, key string) (string, error) {
+		if namespace == "k8sgpt" && name == "openai" && key == "api-key" {
+			return "secret-key", nil
+		}
+		return "", fmt.Errorf("secret %s/%s has no key %s", namespace, name, key)
+	}
+
+	tests := []struct {
+		password string
+		expected string
+		err      string
+	}{
+		{password: "sk-plain", expected: "sk-plain"},
+		{password: "env:K8SGPT_TEST_KEY", expected: "env-key"},
+		{password: "env:K8SGPT_TEST_UNSET", err: "environment variable K8SGPT_TEST_UNSET is not set"},
+		{password: "file:" + file, expected: "file-key"},
+		{password: `exec:echo {"ServerURL":"https://api.openai.com","Username":"k8sgpt","Secret":"helper-key"}`, expected: "helper-key"},
+		{password: `exec:echo {"kind":"ExecCredential","status":{"token":"token-key"}}`, expected: "token-key"},
+		{password: "exec:echo plain-key", expected: "plain-key"},
+		{password: `exec:echo {"Username":"k8sgpt"}`, err: "echo printed no password"},
+		{password: "k8s:k8sgpt/openai/api-key", expected: "secret-key"},
+		{password: "k8s:k8sgpt/openai/token", err: "secret k8sgpt/openai has no key token"},
+		{password: "k8s:k8sgpt/openai", err: `invalid secret reference "k8sgpt/openai", expected k8s:namespace/secret/key`},
+		{password: "file:", err: `invalid password reference "file:", missing the file value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			resolved, err := ResolvePassword(context.Background(), tt.password, secrets)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, resolved)
+		})
+	}
+}
+
+func TestValidatePasswordRef(t *testing.T) {
+	require.NoError(t, ValidatePasswordRef("env:OPENAI_KEY"))
+	require.NoError(t, ValidatePasswordRef("k8s:default/openai/key"))
+	require.EqualError(t, ValidatePasswordRef("sk-plain"), `invalid password reference "sk-plain", expected one of env:, file:, exec:, k8s:`)
+	require.Error(t, ValidatePasswordRef("k8s:openai/key"))
+	require.False(t, IsPasswordRef("https://example.com"))
+}
@@ -59,6 +59,41 @@ const (
 	raw_promt = `{"language": "%s","message": "%s","prompt": "%s"}`
 )
 
+// RemediationPrompt asks for a patch fixing an object, given its kind, its
+// YAML, its failures, their explanation and the language of the description.
+const RemediationPrompt = `The following Kubernetes %s delimited by triple dashes has problems.
+--- %s ---
+Problems: --- %s ---
+Explanation: --- %s ---
+Propose the smallest patch of this object fixing the problems, such as setting resource limits, fixing an image tag or setting a seccompProfile.
+Respond only with a JSON object, without markdown, of the form:
+{"type": "{strategic for a strategic merge patch, or json for a JSON patch}", "patch": {the patch object, or the array of JSON patch operations}, "description": "{What the patch changes, in %s language}"}
+If the problems can't be fixed by patching this object, respond with {"type": "none", "description": "{Why}"}.`
+
+// BatchPrompt asks for the explanations of several failures at once, given
+// the language, the failures preceded by their ID and the form of an answer,
+// BatchAnswer or BatchStructuredAnswer.
+const BatchPrompt = `Simplify the following Kubernetes error messages written in --- %s --- language. Each of them is delimited by triple dashes and preceded by its ID.
+%s
+For each of them, provide the most possible solution in a step by step style in no more than 280 characters.
+Respond only with a JSON object, without markdown, mapping the ID of each error message to %s.`
+
+const (
+	BatchAnswer           = `a string of the form "Error: {Explain error here} Solution: {Step by step solution here}"`
+	BatchStructuredAnswer = `an object of the form {"summary": "{Explain error here}", "rootCause": "{Probable root cause}", "steps": ["{Ordered steps to fix the error}"], "commands": ["{Suggested kubectl commands}"], "confidence": "{low, medium or high}"}`
+)
+
+// StructuredPrompt is appended to the prompts to request a structured
+// explanation, in place of the output format they ask for.
+const StructuredPrompt = `Ignore any output format requested above. Respond only with a JSON object, without markdown, of the form:
+{"summary": "{Explain error here}", "rootCause": "{Probable root cause}", "steps": ["{Ordered steps to fix the error}"], "commands": ["{Suggested kubectl commands}"], "confidence": "{low, medium or high}"}`
+
+// SummaryPrompt asks to summarize the earlier turns of an interactive
+// conversation, given the summary of the turns before them and the turns.
+const SummaryPrompt = `Summarize the following conversation about the problems of a Kubernetes cluster in no more than 150 words, keeping the findings, the resources involved and the open questions.
+Summary of the earlier conversation: --- %s ---
+Conversation: --- %s ---`
+
 var PromptMap = map[string]string{
 	"raw":                           raw_promt,
 	"default":                       default_prompt,
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// PromptConfig is an entry of the `prompts` section of the configuration
+// file. It replaces the built-in prompt of the results matching all of its
+// non-empty selectors, with a text/template given inline or read from File.
+//
+// The template is executed with the variables kind, name, namespace, parent,
+// language, failures and events, e.g. `{{.kind}} {{join .failures "\n"}}`.
+type PromptConfig struct {
+	Kind     string `mapstructure:"kind" yaml:"kind,omitempty"`
+	Analyzer string `mapstructure:"analyzer" yaml:"analyzer,omitempty"`
+	Language string `mapstructure:"language" yaml:"language,omitempty"`
+	Template string `mapstructure:"template" yaml:"template,omitempty"`
+	File     string `mapstructure:"file" yaml:"file,omitempty"`
+}
+
+// Selector describes the results the prompt applies to, e.g. "kind=Pod".
+func (p PromptConfig) Selector() string {
+	var selectors []string
+	if p.Kind != "" {
+		selectors = append(selectors, "kind="+p.Kind)
+	}
+	if p.Analyzer != "" {
+		selectors = append(selectors, "analyzer="+p.Analyzer)
+	}
+	if p.Language != "" {
+		selectors = append(selectors, "language="+p.Language)
+	}
+	if len(selectors) == 0 {
+		return "default"
+	}
+	return strings.Join(selectors, ",")
+}
+
+// SameSelector reports whether p and other apply to the same results.
+func (p PromptConfig) SameSelector(other PromptConfig) bool {
+	return strings.EqualFold(p.Kind, other.Kind) &&
+		strings.EqualFold(p.Analyzer, other.Analyzer) &&
+		strings.EqualFold(p.Language, other.Language)
+}
+
+// Matches reports whether the prompt applies to a result of the given kind
+// and analyzer, explained in language.
+func (p PromptConfig) Matches(kind, analyzer, language string) bool {
+	return matchSelector(p.Kind, kind) && matchSelector(p.Analyzer, analyzer) && matchSelector(p.Language, language)
+}
+
+func matchSelector(selector, value string) bool {
+	return selector == "" || strings.EqualFold(selector, value)
+}
+
+// specificity ranks the prompts matching a result, the analyzer being the
+// most specific selector and the language the least.
+func (p PromptConfig) specificity() int {
+	n := 0
+	if p.Analyzer != "" {
+		n += 4
+	}
+	if p.Kind != "" {
+		n += 2
+	}
+	if p.Language != "" {
+		n++
+	}
+	return n
+}
+
+// Source returns the template, reading it from File if set.
+func (p PromptConfig) Source() (string, error) {
+	if p.File == "" {
+		return p.Template, nil
+	}
+	data, err := os.ReadFile(p.File)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ParsePromptTemplate parses a prompt template, see PromptConfig.
+func ParsePromptTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Option("missingkey=error").
+		Parse(text)
+}
+
+// PromptTemplate is a parsed user-defined prompt.
+type PromptTemplate struct {
+	Config PromptConfig
+	Source string
+	tmpl   *template.Template
+}
+
+// UsesEvents reports whether the template refers to the events of the object,
+// which have to be fetched from the cluster.
+func (p *PromptTemplate) UsesEvents() bool {
+	return strings.Contains(p.Source, ".events")
+}
+
+// Render executes the template with the given variables.
+func (p *PromptTemplate) Render(vars map[string]interface{}) (string, error) {
+	var prompt strings.Builder
+	if err := p.tmpl.Execute(&prompt, vars); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(prompt.String()), nil
+}
+
+// Prompts holds the user-defined prompts.
+type Prompts struct {
+	templates []*PromptTemplate
+}
+
+// NewPrompts parses the configured prompts.
+func NewPrompts(configs []PromptConfig) (*Prompts, error) {
+	p := &Prompts{}
+	for _, config := range configs {
+		source, err := config.Source()
+		if err != nil {
+			return nil, fmt.Errorf("reading prompt %s: %w", config.Selector(), err)
+		}
+		tmpl, err := ParsePromptTemplate(config.Selector(), source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prompt %s: %w", config.Selector(), err)
+		}
+		p.templates = append(p.templates, &PromptTemplate{Config: config, Source: source, tmpl: tmpl})
+	}
+	return p, nil
+}
+
+// Find returns the most specific prompt matching a result, or nil if the
+// built-in prompt should be used.
+func (p *Prompts) Find(kind, analyzer, language string) *PromptTemplate {
+	if p == nil {
+		return nil
+	}
+	var found *PromptTemplate
+	for _, tmpl := range p.templates {
+		if !tmpl.Config.Matches(kind, analyzer, language) {
+			continue
+		}
+		if found == nil || tmpl.Config.specificity() > found.Config.specificity() {
+			found = tmpl
+		}
+	}
+	return found
+}
+
+// AnalyzerPrompts returns the prompts of specific analyzers used for the
+// results of kind explained in language, one per analyzer.
+func (p *Prompts) AnalyzerPrompts(kind, language string) []*PromptTemplate {
+	if p == nil {
+		return nil
+	}
+	var found []*PromptTemplate
+	seen := map[string]bool{}
+	for _, tmpl := range p.templates {
+		analyzer := strings.ToLower(tmpl.Config.Analyzer)
+		if analyzer == "" || seen[analyzer] {
+			continue
+		}
+		seen[analyzer] = true
+		if prompt := p.Find(kind, analyzer, language); prompt != nil && prompt.Config.Analyzer != "" {
+			found = append(found, prompt)
+		}
+	}
+	return found
+}
+
+// BuiltinPrompt returns the compiled-in prompt used for kind.
+func BuiltinPrompt(kind string) string {
+	if prompt, ok := PromptMap[kind]; ok {
+		return prompt
+	}
+	return PromptMap["default"]
+}
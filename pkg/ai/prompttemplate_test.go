@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrompts(t *testing.T) {
+	prompts, err := NewPrompts([]PromptConfig{
+		{Template: "default {{.kind}}"},
+		{Language: "German", Template: "german {{.kind}}"},
+		{Kind: "Pod", Template: "pod {{.name}}: {{join .failures \"; \"}}"},
+		{Kind: "Pod", Analyzer: "PodSecurity", Template: "security {{.name}}"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		kind, analyzer, language string
+		expected                 string
+	}{
+		{"Service", "Service", "english", "default Service"},
+		{"Service", "Service", "german", "german Service"},
+		{"Pod", "Pod", "german", "pod web: failed; crashed"},
+		{"pod", "podsecurity", "english", "security web"},
+	}
+	for _, tt := range tests {
+		tmpl := prompts.Find(tt.kind, tt.analyzer, tt.language)
+		require.NotNil(t, tmpl)
+		prompt, err := tmpl.Render(map[string]interface{}{
+			"kind":     tt.kind,
+			"name":     "web",
+			"failures": []string{"failed", "crashed"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, prompt)
+	}
+
+	var analyzers []string
+	for _, tmpl := range prompts.AnalyzerPrompts("Pod", "english") {
+		analyzers = append(analyzers, tmpl.Config.Selector())
+	}
+	require.Equal(t, []string{"kind=Pod,analyzer=PodSecurity"}, analyzers)
+	require.Empty(t, prompts.AnalyzerPrompts("Service", "english"))
+
+	prompts, err = NewPrompts([]PromptConfig{{Kind: "Pod", Template: "{{.nmae}}"}})
+	require.NoError(t, err)
+	require.Nil(t, prompts.Find("Service", "Service", "english"))
+	_, err = prompts.Find("Pod", "Pod", "english").Render(map[string]interface{}{"name": "web"})
+	require.ErrorContains(t, err, `map has no entry for key "nmae"`)
+
+	_, err = NewPrompts([]PromptConfig{{Kind: "Pod", Template: "{{.name"}})
+	require.ErrorContains(t, err, "invalid prompt kind=Pod")
+	_, err = NewPrompts([]PromptConfig{{File: "does-not-exist.tmpl"}})
+	require.ErrorContains(t, err, "reading prompt default")
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"math"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+var (
+	rateLimitersMutex sync.Mutex
+	rateLimiters      = map[string]*rate.Limiter{}
+)
+
+// RateLimiter returns the token bucket shared by all the requests to the
+// provider, allowing requestsPerMinute requests with bursts of up to a
+// second's worth of them. It returns nil if requestsPerMinute is not positive.
+func RateLimiter(provider string, requestsPerMinute float64) *rate.Limiter {
+	if requestsPerMinute <= 0 {
+		return nil
+	}
+	limit := rate.Limit(requestsPerMinute / 60)
+	burst := int(math.Max(1, math.Ceil(requestsPerMinute/60)))
+
+	rateLimitersMutex.Lock()
+	defer rateLimitersMutex.Unlock()
+	limiter, ok := rateLimiters[provider]
+	if !ok {
+		limiter = rate.NewLimiter(limit, burst)
+		rateLimiters[provider] = limiter
+	} else if limiter.Limit() != limit || limiter.Burst() != burst {
+		// The provider was reconfigured.
+		limiter.SetLimit(limit)
+		limiter.SetBurst(burst)
+	}
+	return limiter
+}
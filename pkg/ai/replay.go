@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"sigs.k8s.io/yaml"
+)
+
+const replayClientName = "replay"
+
+// Cassette holds recorded completions.
+type Cassette struct {
+	Completions []RecordedCompletion `json:"completions"`
+}
+
+// RecordedCompletion is a completion recorded by a Recorder. Its key is the
+// util.GetCacheKey of the prompt, independent of the provider, so that the
+// completions of any provider can be replayed.
+type RecordedCompletion struct {
+	Key              string `json:"key"`
+	Provider         string `json:"provider"`
+	Prompt           string `json:"prompt"`
+	Completion       string `json:"completion"`
+	PromptTokens     int    `json:"promptTokens,omitempty"`
+	CompletionTokens int    `json:"completionTokens,omitempty"`
+}
+
+func cassetteKey(prompt string) string {
+	return util.GetCacheKey("", "", prompt)
+}
+
+// LoadCassette reads a cassette file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cassette Cassette
+	if err := yaml.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+// ReplayClient answers with the completions of a cassette, whose path is the
+// base URL of the provider.
+type ReplayClient struct {
+	nopCloser
+
+	completions map[string]RecordedCompletion
+}
+
+func (c *ReplayClient) Configure(config IAIConfig) error {
+	path := config.GetBaseURL()
+	if path == "" {
+		return errors.New("the replay backend requires the path of a cassette as baseurl")
+	}
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		return err
+	}
+	c.completions = map[string]RecordedCompletion{}
+	for _, completion := range cassette.Completions {
+		c.completions[completion.Key] = completion
+	}
+	return nil
+}
+
+func (c *ReplayClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	key := cassetteKey(prompt)
+	completion, ok := c.completions[key]
+	if !ok {
+		return "", fmt.Errorf("no recorded completion for the prompt (key %s)", key)
+	}
+	if completion.PromptTokens != 0 || completion.CompletionTokens != 0 {
+		reportUsage(ctx, Usage{PromptTokens: completion.PromptTokens, CompletionTokens: completion.CompletionTokens})
+	}
+	return completion.Completion, nil
+}
+
+func (c *ReplayClient) GetName() string {
+	return replayClientName
+}
+
+// Recorder wraps a client, recording every completion it gives to a cassette
+// file that the replay backend can read. The cassette is written by Save.
+type Recorder struct {
+	client IAI
+	path   string
+
+	mutex    sync.Mutex
+	cassette Cassette
+	index    map[string]int
+}
+
+// NewRecorder wraps client, recording its completions to the cassette at
+// path. The completions already recorded there are kept.
+func NewRecorder(client IAI, path string) (*Recorder, error) {
+	r := &Recorder{client: client, path: path, index: map[string]int{}}
+	cassette, err := LoadCassette(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if cassette != nil {
+		r.cassette = *cassette
+		for i, completion := range cassette.Completions {
+			r.index[completion.Key] = i
+		}
+	}
+	return r, nil
+}
+
+func (r *Recorder) Configure(config IAIConfig) error {
+	return r.client.Configure(config)
+}
+
+func (r *Recorder) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	completion, err := r.client.GetCompletion(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	r.record(r.client.GetName(), prompt, completion, Usage{})
+	return completion, nil
+}
+
+func (r *Recorder) StreamCompletion(ctx context.Context, prompt string, w io.Writer) (string, error) {
+	completion, err := StreamCompletion(ctx, r.client, prompt, w)
+	if err != nil {
+		return "", err
+	}
+	r.record(r.client.GetName(), prompt, completion, Usage{})
+	return completion, nil
+}
+
+// GetName returns the name of the wrapped client.
+func (r *Recorder) GetName() string {
+	return r.client.GetName()
+}
+
+func (r *Recorder) Close() {
+	r.client.Close()
+}
+
+// record adds a completion to the cassette, replacing the one of the same
+// prompt.
+func (r *Recorder) record(provider, prompt, completion string, usage Usage) {
+	recorded := RecordedCompletion{
+		Key:        cassetteKey(prompt),
+		Provider:   provider,
+		Prompt:     prompt,
+		Completion: completion,
+	}
+	if !usage.Estimated {
+		recorded.PromptTokens = usage.PromptTokens
+		recorded.CompletionTokens = usage.CompletionTokens
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if i, ok := r.index[recorded.Key]; ok {
+		r.cassette.Completions[i] = recorded
+	} else {
+		r.index[recorded.Key] = len(r.cassette.Completions)
+		r.cassette.Completions = append(r.cassette.Completions, recorded)
+	}
+}
+
+// Save writes the cassette. It is written to a temporary file first, so that
+// an interrupted write leaves the previous cassette intact.
+func (r *Recorder) Save() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	data, err := yaml.Marshal(r.cassette)
+	if err != nil {
+		return err
+	}
+	if err := util.WriteFileAtomic(r.path, data, 0600); err != nil {
+		return fmt.Errorf("recording completions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// usageClient reports the usage of its completions.
+type usageClient struct {
+	fakeClient
+}
+
+func (c *usageClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	reportUsage(ctx, Usage{PromptTokens: 10, CompletionTokens: 5})
+	return c.fakeClient.GetCompletion(ctx, prompt)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	primary := &usageClient{fakeClient{name: t.Name() + "-primary"}}
+	secondary := &fakeClient{name: t.Name() + "-secondary"}
+	recorder, err := NewRecorder(NewFallbackClient([]FallbackProvider{{Client: primary}, {Client: secondary}}), path)
+	require.NoError(t, err)
+	require.Equal(t, []string{primary.name, secondary.name}, ProviderNames(recorder))
+
+	completion, err := Completion(context.Background(), recorder, "first", nil)
+	require.NoError(t, err)
+	require.Equal(t, CompletionResult{
+		Text:     primary.name + ": first",
+		Provider: primary.name,
+		Usage:    Usage{PromptTokens: 10, CompletionTokens: 5},
+	}, completion)
+	// Completions of the client used directly are recorded too.
+	text, err := recorder.GetCompletion(context.Background(), "second")
+	require.NoError(t, err)
+	require.Equal(t, primary.name+": second", text)
+	require.NoError(t, recorder.Save())
+
+	// Recording again keeps the completions of the cassette.
+	recorder, err = NewRecorder(secondary, path)
+	require.NoError(t, err)
+	_, err = Completion(context.Background(), recorder, "third", nil)
+	require.NoError(t, err)
+	require.NoError(t, recorder.Save())
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	cassette, err := LoadCassette(path)
+	require.NoError(t, err)
+	require.Len(t, cassette.Completions, 3)
+	require.Equal(t, RecordedCompletion{
+		Key:              cassetteKey("first"),
+		Provider:         primary.name,
+		Prompt:           "first",
+		Completion:       primary.name + ": first",
+		PromptTokens:     10,
+		CompletionTokens: 5,
+	}, cassette.Completions[0])
+
+	replay := &ReplayClient{}
+	require.ErrorContains(t, replay.Configure(&AIProvider{}), "requires the path of a cassette")
+	require.NoError(t, replay.Configure(&AIProvider{BaseURL: path}))
+	completion, err = Completion(context.Background(), replay, "first", nil)
+	require.NoError(t, err)
+	require.Equal(t, CompletionResult{
+		Text:     primary.name + ": first",
+		Provider: "replay",
+		Usage:    Usage{PromptTokens: 10, CompletionTokens: 5},
+	}, completion)
+	text, err = replay.GetCompletion(context.Background(), "third")
+	require.NoError(t, err)
+	require.Equal(t, secondary.name+": third", text)
+	_, err = replay.GetCompletion(context.Background(), "unknown")
+	require.ErrorContains(t, err, "no recorded completion for the prompt")
+}
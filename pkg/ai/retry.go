@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	ollama "github.com/ollama/ollama/api"
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	defaultMaxRetries = 5
+	defaultBaseDelay  = time.Second
+	defaultMaxDelay   = time.Minute
+)
+
+// RetryTransport is an http.RoundTripper retrying the requests that were
+// rate limited or hit an unavailable backend, with an exponential backoff
+// honouring the Retry-After header.
+type RetryTransport struct {
+	Origin     http.RoundTripper
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// NewRetryTransport returns a RetryTransport with the default backoff.
+func NewRetryTransport(origin http.RoundTripper) *RetryTransport {
+	if origin == nil {
+		origin = http.DefaultTransport
+	}
+	return &RetryTransport{
+		Origin:     origin,
+		MaxRetries: defaultMaxRetries,
+		BaseDelay:  defaultBaseDelay,
+		MaxDelay:   defaultMaxDelay,
+	}
+}
+
+// proxyTransport returns the default transport with its proxy set to
+// proxyEndpoint, or nil to use the default transport, and its proxy from the
+// environment, if proxyEndpoint is empty.
+func proxyTransport(proxyEndpoint string) (http.RoundTripper, error) {
+	if proxyEndpoint == "" {
+		return nil, nil
+	}
+	proxyUrl, err := url.Parse(proxyEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyUrl)
+	return transport, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.Origin.RoundTrip(req)
+		if err != nil || attempt >= t.MaxRetries || !retryableStatus(resp.StatusCode) {
+			return resp, err
+		}
+		// The body can only be sent again if it can be rewound.
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+
+		delay := t.delay(attempt, resp.Header.Get("Retry-After"))
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// delay returns how long to wait before the next attempt: the Retry-After
+// duration if the server sent one, or else an exponential backoff with jitter.
+func (t *RetryTransport) delay(attempt int, retryAfter string) time.Duration {
+	if retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return min(time.Duration(seconds)*time.Second, t.MaxDelay)
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return min(max(time.Until(date), 0), t.MaxDelay)
+		}
+	}
+	backoff := t.BaseDelay << attempt
+	if backoff <= 0 || backoff > t.MaxDelay {
+		backoff = t.MaxDelay
+	}
+	// Spread the retries of concurrent requests.
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// IsRateLimited reports whether err is the AI provider refusing a request
+// because of its rate limit or quota.
+func IsRateLimited(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	var requestErr *openai.RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	var statusErr ollama.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	// The other SDKs only report the status code in their messages.
+	return err != nil && strings.Contains(err.Error(), "status code: 429")
+}
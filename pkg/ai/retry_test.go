@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ollama "github.com/ollama/ollama/api"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransport(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "foo prompt", string(body))
+		if requests < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewRetryTransport(nil)}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("foo prompt"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 3, requests)
+}
+
+func TestRetryTransport_GivesUp(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport := NewRetryTransport(nil)
+	transport.BaseDelay = time.Millisecond
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Equal(t, defaultMaxRetries+1, requests)
+
+	// Client errors are not retried.
+	requests = 0
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 1, requests)
+}
+
+func TestRetryTransport_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: NewRetryTransport(nil)}).Do(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRetryTransport_Delay(t *testing.T) {
+	transport := NewRetryTransport(nil)
+	require.Equal(t, 2*time.Second, transport.delay(0, "2"))
+	require.Equal(t, defaultMaxDelay, transport.delay(0, "3600"))
+	for attempt := 0; attempt < 10; attempt++ {
+		delay := transport.delay(attempt, "")
+		require.LessOrEqual(t, delay, defaultMaxDelay)
+		require.GreaterOrEqual(t, delay, min(defaultBaseDelay<<attempt, defaultMaxDelay)/2)
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	require.True(t, IsRateLimited(fmt.Errorf("wrapped: %w", &openai.APIError{HTTPStatusCode: http.StatusTooManyRequests})))
+	require.True(t, IsRateLimited(&openai.RequestError{HTTPStatusCode: http.StatusTooManyRequests}))
+	require.True(t, IsRateLimited(ollama.StatusError{StatusCode: http.StatusTooManyRequests}))
+	require.True(t, IsRateLimited(errors.New("error, status code: 429, message: quota")))
+	require.False(t, IsRateLimited(&openai.APIError{HTTPStatusCode: http.StatusInternalServerError}))
+	require.False(t, IsRateLimited(nil))
+}
+
+func TestRateLimiter(t *testing.T) {
+	require.Nil(t, RateLimiter("test", 0))
+
+	limiter := RateLimiter("test", 600)
+	require.InDelta(t, 10, float64(limiter.Limit()), 0.001)
+	require.Equal(t, 10, limiter.Burst())
+	require.Same(t, limiter, RateLimiter("test", 30))
+	require.InDelta(t, 0.5, float64(limiter.Limit()), 0.001)
+	require.Equal(t, 1, limiter.Burst())
+}
+
+func TestProxyTransport(t *testing.T) {
+	transport, err := proxyTransport("")
+	require.NoError(t, err)
+	require.Nil(t, transport)
+
+	transport, err = proxyTransport("http://proxy.internal:3128")
+	require.NoError(t, err)
+	proxy, err := transport.(*http.Transport).Proxy(httptest.NewRequest(http.MethodGet, "https://api.openai.com", nil))
+	require.NoError(t, err)
+	require.Equal(t, "http://proxy.internal:3128", proxy.String())
+	// The timeouts of the default transport are kept.
+	require.Equal(t, http.DefaultTransport.(*http.Transport).TLSHandshakeTimeout, transport.(*http.Transport).TLSHandshakeTimeout)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIClient_StreamCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, true, req["stream"])
+		// OpenAI compatible servers may reject the stream options.
+		require.NotContains(t, req, "stream_options")
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, token := range []string{"Error: ", "the image ", "does not exist."} {
+			chunk, err := json.Marshal(map[string]interface{}{
+				"choices": []map[string]interface{}{{"delta": map[string]string{"content": token}}},
+			})
+			require.NoError(t, err)
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, `data: {"choices": [], "usage": {"prompt_tokens": 12, "completion_tokens": 7, "total_tokens": 19}}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	for _, client := range []IAI{&OpenAIClient{}, &LocalAIClient{}} {
+		require.NoError(t, client.Configure(&mockConfig{baseURL: server.URL}))
+		require.True(t, CanStream(client))
+
+		var output strings.Builder
+		completion, err := Completion(context.Background(), client, "foo prompt", &output)
+		require.NoError(t, err)
+		require.Equal(t, "Error: the image does not exist.", completion.Text)
+		require.Equal(t, completion.Text, output.String())
+		require.Equal(t, Usage{PromptTokens: 12, CompletionTokens: 7}, completion.Usage)
+	}
+}
+
+func TestIsOpenAIAPI(t *testing.T) {
+	require.True(t, isOpenAIAPI("https://api.openai.com/v1"))
+	require.False(t, isOpenAIAPI("http://localhost:8080/v1"))
+	require.False(t, isOpenAIAPI("https://api.openai.com.example.com/v1"))
+
+	client := &OpenAIClient{}
+	require.NoError(t, client.Configure(&mockConfig{}))
+	require.True(t, client.streamUsage)
+}
+
+func TestOllamaClient_StreamCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/generate", r.URL.Path)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, token := range []string{"Error: ", "the image ", "does not exist."} {
+			fmt.Fprintf(w, `{"model":"llama3","response":%q,"done":false}`+"\n", token)
+		}
+		fmt.Fprint(w, `{"model":"llama3","response":"","done":true,"prompt_eval_count":12,"eval_count":7}`+"\n")
+	}))
+	defer server.Close()
+
+	client := &OllamaClient{}
+	require.NoError(t, client.Configure(&mockConfig{baseURL: server.URL}))
+
+	var output strings.Builder
+	completion, err := Completion(context.Background(), client, "foo prompt", &output)
+	require.NoError(t, err)
+	require.Equal(t, "Error: the image does not exist.", completion.Text)
+	require.Equal(t, completion.Text, output.String())
+	require.Equal(t, Usage{PromptTokens: 12, CompletionTokens: 7}, completion.Usage)
+}
+
+func TestStreamCompletion_NotStreaming(t *testing.T) {
+	client := &NoOpAIClient{}
+	require.False(t, CanStream(client))
+
+	var output strings.Builder
+	completion, err := StreamCompletion(context.Background(), client, "foo", &output)
+	require.NoError(t, err)
+	require.Equal(t, "I am a noop response to the prompt foo", completion)
+	require.Equal(t, completion, output.String())
+
+	_, err = StreamCompletion(context.Background(), client, "foo", errWriter{})
+	require.Error(t, err)
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestCompletion_EstimatedUsage(t *testing.T) {
+	completion, err := Completion(context.Background(), &NoOpAIClient{}, "foo prompt", nil)
+	require.NoError(t, err)
+	require.Equal(t, "I am a noop response to the prompt foo prompt", completion.Text)
+	require.Equal(t, Usage{PromptTokens: 3, CompletionTokens: 12, Estimated: true}, completion.Usage)
+
+	pricing := Pricing{InputCost: 2.5, OutputCost: 10}
+	require.InDelta(t, 0.0000075+0.00012, pricing.Cost(completion.Usage), 1e-12)
+}
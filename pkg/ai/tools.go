@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// The roles of the messages of a conversation.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool"
+)
+
+// ChatMessage is a message of a conversation with the model.
+type ChatMessage struct {
+	Role    string
+	Content string
+	// ToolCalls are the tools the assistant asks to call.
+	ToolCalls []ToolCall
+	// ToolCallID is the call a tool message answers.
+	ToolCallID string
+}
+
+// Tool is a function the model may ask to call.
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters is the JSON schema of the arguments.
+	Parameters map[string]interface{}
+}
+
+// ToolCall is a call of a tool asked by the model.
+type ToolCall struct {
+	ID   string
+	Name string
+	// Arguments are JSON encoded.
+	Arguments string
+}
+
+// IToolCallingAI is implemented by the clients whose backend can call tools.
+type IToolCallingAI interface {
+	IAI
+	// Chat answers the conversation, possibly asking to call some of the
+	// tools; their results are then added to the conversation to continue.
+	Chat(ctx context.Context, messages []ChatMessage, tools []Tool) (ChatMessage, error)
+}
+
+// ToolCalling returns the client as an IToolCallingAI, if it can call tools.
+// With fallbacks, only the primary provider is used, and chats through a
+// Recorder are not recorded.
+func ToolCalling(client IAI) (IToolCallingAI, bool) {
+	if recorder, ok := client.(*Recorder); ok {
+		client = recorder.client
+	}
+	if fallback, ok := client.(*FallbackClient); ok {
+		client = fallback.providers[0].Client
+	}
+	toolCalling, ok := client.(IToolCallingAI)
+	return toolCalling, ok
+}
+
+func (c *OpenAIClient) Chat(ctx context.Context, messages []ChatMessage, tools []Tool) (ChatMessage, error) {
+	return chatWithTools(ctx, c.client, c.completionRequest(""), messages, tools)
+}
+
+func (c *AzureAIClient) Chat(ctx context.Context, messages []ChatMessage, tools []Tool) (ChatMessage, error) {
+	return chatWithTools(ctx, c.client, c.completionRequest(""), messages, tools)
+}
+
+// chatWithTools sends a conversation to an OpenAI compatible backend, with
+// the parameters of req.
+func chatWithTools(ctx context.Context, client *openai.Client, req openai.ChatCompletionRequest, messages []ChatMessage, tools []Tool) (ChatMessage, error) {
+	req.Messages = nil
+	for _, message := range messages {
+		m := openai.ChatCompletionMessage{
+			Role:       message.Role,
+			Content:    message.Content,
+			ToolCallID: message.ToolCallID,
+		}
+		for _, call := range message.ToolCalls {
+			m.ToolCalls = append(m.ToolCalls, openai.ToolCall{
+				ID:       call.ID,
+				Type:     openai.ToolTypeFunction,
+				Function: openai.FunctionCall{Name: call.Name, Arguments: call.Arguments},
+			})
+		}
+		req.Messages = append(req.Messages, m)
+	}
+	for _, tool := range tools {
+		req.Tools = append(req.Tools, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+
+	resp, err := client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	reportOpenAIUsage(ctx, &resp.Usage)
+	if len(resp.Choices) == 0 {
+		return ChatMessage{Role: RoleAssistant}, nil
+	}
+	answer := resp.Choices[0].Message
+	message := ChatMessage{Role: RoleAssistant, Content: answer.Content}
+	for _, call := range answer.ToolCalls {
+		message.ToolCalls = append(message.ToolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+	return message, nil
+}
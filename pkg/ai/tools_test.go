@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIClient_Chat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Role       string `json:"role"`
+				Content    string `json:"content"`
+				ToolCallID string `json:"tool_call_id"`
+				ToolCalls  []struct {
+					ID string `json:"id"`
+				} `json:"tool_calls"`
+			} `json:"messages"`
+			Tools []struct {
+				Type     string `json:"type"`
+				Function struct {
+					Name       string                 `json:"name"`
+					Parameters map[string]interface{} `json:"parameters"`
+				} `json:"function"`
+			} `json:"tools"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Len(t, req.Tools, 1)
+		require.Equal(t, "function", req.Tools[0].Type)
+		require.Equal(t, "list_events", req.Tools[0].Function.Name)
+		require.Equal(t, "object", req.Tools[0].Function.Parameters["type"])
+
+		last := req.Messages[len(req.Messages)-1]
+		if last.Role == RoleTool {
+			require.Equal(t, "call-1", last.ToolCallID)
+			require.Equal(t, "call-1", req.Messages[len(req.Messages)-2].ToolCalls[0].ID)
+			fmt.Fprintf(w, `{"choices": [{"message": {"role": "assistant", "content": "The image does not exist: %s"}}]}`, last.Content)
+			return
+		}
+		fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "tool_calls": [`+
+			`{"id": "call-1", "type": "function", "function": {"name": "list_events", "arguments": "{\"namespace\": \"default\"}"}}]}}]}`)
+	}))
+	defer server.Close()
+
+	for _, client := range []IAI{&OpenAIClient{}, &LocalAIClient{}, NewFallbackClient([]FallbackProvider{{Client: &OpenAIClient{}}})} {
+		if fallback, ok := client.(*FallbackClient); ok {
+			require.NoError(t, fallback.providers[0].Client.Configure(&mockConfig{baseURL: server.URL}))
+		} else {
+			require.NoError(t, client.Configure(&mockConfig{baseURL: server.URL}))
+		}
+		chat, ok := ToolCalling(client)
+		require.True(t, ok)
+
+		tools := []Tool{{Name: "list_events", Parameters: map[string]interface{}{"type": "object"}}}
+		messages := []ChatMessage{{Role: RoleUser, Content: "Why does the pod fail?"}}
+		answer, err := chat.Chat(context.Background(), messages, tools)
+		require.NoError(t, err)
+		require.Equal(t, ChatMessage{
+			Role:      RoleAssistant,
+			ToolCalls: []ToolCall{{ID: "call-1", Name: "list_events", Arguments: `{"namespace": "default"}`}},
+		}, answer)
+
+		messages = append(messages, answer, ChatMessage{Role: RoleTool, Content: "ErrImagePull", ToolCallID: "call-1"})
+		answer, err = chat.Chat(context.Background(), messages, tools)
+		require.NoError(t, err)
+		require.Equal(t, ChatMessage{Role: RoleAssistant, Content: "The image does not exist: ErrImagePull"}, answer)
+	}
+
+	_, ok := ToolCalling(&OllamaClient{})
+	require.False(t, ok)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ai
+
+import (
+	"context"
+	"unicode/utf8"
+)
+
+// Usage is the number of tokens used by a completion.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	// Estimated is set when the provider did not report the usage, and it
+	// was estimated from the length of the prompt and of the completion.
+	Estimated bool
+}
+
+// Pricing is the price of a million tokens of a provider.
+type Pricing struct {
+	InputCost  float64
+	OutputCost float64
+}
+
+// Cost returns the price of the usage.
+func (p Pricing) Cost(usage Usage) float64 {
+	return (float64(usage.PromptTokens)*p.InputCost + float64(usage.CompletionTokens)*p.OutputCost) / 1e6
+}
+
+type usageKey struct{}
+
+// reportUsage records the usage reported by the provider for the completion
+// requested with ctx.
+func reportUsage(ctx context.Context, usage Usage) {
+	if u, ok := ctx.Value(usageKey{}).(*Usage); ok {
+		*u = usage
+	}
+}
+
+// EstimateTokens estimates the number of tokens of a text, counting about 4
+// characters per token.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
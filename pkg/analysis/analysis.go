@@ -18,6 +18,9 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -33,6 +36,7 @@ import (
 	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/viper"
+	"golang.org/x/time/rate"
 )
 
 type Analysis struct {
@@ -48,10 +52,31 @@ type Analysis struct {
 	Cache              cache.ICache
 	Explain            bool
 	MaxConcurrency     int
+	Batch              bool   // Explain several results per prompt
 	AnalysisAIProvider string // The name of the AI Provider used for this analysis
 	WithDoc            bool
 	WithStats          bool
 	Stats              []common.AnalysisStats
+	Suppressed         int // The number of problems dropped by the suppressions file
+	exclusions         *exclusions
+	suppressions       *suppressions
+	// clusters holds a client per kubecontext when analyzing several clusters.
+	clusters []cluster
+	// rateLimiter limits the requests to the AI provider, if configured.
+	rateLimiter *rate.Limiter
+	// pricing holds the prices of the AI providers that have some.
+	pricing map[string]ai.Pricing
+	usage   *aiUsage
+	// budget limits the AI calls, if set.
+	budget *budget
+	// prompts are the user-defined prompts replacing the built-in ones.
+	prompts *ai.Prompts
+	// structured requests structured explanations, for the JSON output.
+	structured bool
+	// contextWindow is the number of tokens of the model's context window.
+	contextWindow int
+	// maxTokens is the maximum number of tokens of a completion.
+	maxTokens int
 }
 
 type (
@@ -65,11 +90,13 @@ const (
 )
 
 type JsonOutput struct {
-	Provider string          `json:"provider"`
-	Errors   AnalysisErrors  `json:"errors"`
-	Status   AnalysisStatus  `json:"status"`
-	Problems int             `json:"problems"`
-	Results  []common.Result `json:"results"`
+	Provider   string          `json:"provider"`
+	Errors     AnalysisErrors  `json:"errors"`
+	Status     AnalysisStatus  `json:"status"`
+	Problems   int             `json:"problems"`
+	Suppressed int             `json:"suppressed,omitempty"`
+	Results    []common.Result `json:"results"`
+	Usage      []AIUsage       `json:"usage,omitempty"`
 }
 
 func NewAnalysis(
@@ -85,13 +112,35 @@ func NewAnalysis(
 	interactiveMode bool,
 	httpHeaders []string,
 	withStats bool,
+	snapshot string,
+	contexts []string,
+	suppressionsFile string,
 ) (*Analysis, error) {
-	// Get kubernetes client from viper.
-	kubecontext := viper.GetString("kubecontext")
-	kubeconfig := viper.GetString("kubeconfig")
-	client, err := kubernetes.NewClient(kubecontext, kubeconfig)
-	if err != nil {
-		return nil, fmt.Errorf("initialising kubernetes client: %w", err)
+	var client *kubernetes.Client
+	var clusters []cluster
+	var clusterErrors []string
+	var err error
+	if snapshot != "" {
+		// Serve the analyzers from a cluster snapshot instead of a live API server.
+		client, err = kubernetes.NewClientFromSnapshot(snapshot)
+		if err != nil {
+			return nil, fmt.Errorf("loading cluster snapshot: %w", err)
+		}
+	} else if len(contexts) > 0 {
+		// Analyze every reachable cluster, unreachable ones are reported as warnings.
+		clusters, clusterErrors = newClusters(contexts, viper.GetString("kubeconfig"), maxConcurrency)
+		if len(clusters) == 0 {
+			return nil, fmt.Errorf("initialising kubernetes clients: %s", strings.Join(clusterErrors, ", "))
+		}
+		client = clusters[0].client
+	} else {
+		// Get kubernetes client from viper.
+		kubecontext := viper.GetString("kubecontext")
+		kubeconfig := viper.GetString("kubeconfig")
+		client, err = kubernetes.NewClient(kubecontext, kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("initialising kubernetes client: %w", err)
+		}
 	}
 
 	// Load remote cache if it is configured.
@@ -104,6 +153,15 @@ func NewAnalysis(
 		cache.DisableCache()
 	}
 
+	var exclusionConfig ExclusionConfig
+	if err := viper.UnmarshalKey("exclusions", &exclusionConfig); err != nil {
+		return nil, err
+	}
+	exclusions, err := newExclusions(exclusionConfig, client)
+	if err != nil {
+		return nil, err
+	}
+
 	a := &Analysis{
 		Context:        context.Background(),
 		Filters:        filters,
@@ -116,7 +174,27 @@ func NewAnalysis(
 		MaxConcurrency: maxConcurrency,
 		WithDoc:        withDoc,
 		WithStats:      withStats,
+		exclusions:     exclusions,
+		clusters:       clusters,
+		Errors:         clusterErrors,
+		usage:          newAIUsage(),
 	}
+
+	// The file given takes precedence over the one of the config.
+	if suppressionsFile == "" {
+		suppressionsFile = viper.GetString("suppressions_file")
+	}
+	if suppressionsFile == "" {
+		if _, err := os.Stat(DefaultSuppressionsFile); err == nil {
+			suppressionsFile = DefaultSuppressionsFile
+		}
+	}
+	if suppressionsFile != "" {
+		if err := a.LoadSuppressions(suppressionsFile); err != nil {
+			return nil, err
+		}
+	}
+
 	if !explain {
 		// Return early if AI use was not requested.
 		return a, nil
@@ -131,6 +209,14 @@ func NewAnalysis(
 		return nil, errors.New("AI provider not specified in configuration. Please run k8sgpt auth")
 	}
 
+	var promptConfigs []ai.PromptConfig
+	if err := viper.UnmarshalKey("prompts", &promptConfigs); err != nil {
+		return nil, err
+	}
+	if a.prompts, err = ai.NewPrompts(promptConfigs); err != nil {
+		return nil, err
+	}
+
 	// Backend string will have high priority than a default provider
 	// Hence, use the default provider only if the backend is not specified by the user.
 	if configAI.DefaultProvider != "" && backend == "" {
@@ -141,27 +227,81 @@ func NewAnalysis(
 		backend = "openai"
 	}
 
+	customHeaders := util.NewHeaders(httpHeaders)
+	aiClient, aiProvider, err := configureAIProvider(configAI, backend, customHeaders)
+	if err != nil {
+		return nil, err
+	}
+	a.AnalysisAIProvider = aiProvider.Name
+	a.contextWindow = aiProvider.ContextWindow
+	a.maxTokens = aiProvider.MaxTokens
+	a.pricing = map[string]ai.Pricing{aiProvider.Name: aiProvider.GetPricing()}
+
+	// Without fallbacks, the client is used directly.
+	providers := []ai.FallbackProvider{{
+		Client:  aiClient,
+		Limiter: ai.RateLimiter(aiProvider.Name, aiProvider.RequestsPerMinute),
+	}}
+	for _, fallback := range configAI.Fallbacks {
+		if fallback == aiProvider.Name {
+			continue
+		}
+		client, provider, err := configureAIProvider(configAI, fallback, customHeaders)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AI fallback: %w", err)
+		}
+		a.pricing[provider.Name] = provider.GetPricing()
+		providers = append(providers, ai.FallbackProvider{
+			Client:  client,
+			Limiter: ai.RateLimiter(provider.Name, provider.RequestsPerMinute),
+		})
+	}
+	if len(providers) == 1 {
+		a.AIClient = aiClient
+		a.rateLimiter = providers[0].Limiter
+	} else {
+		a.AIClient = ai.NewFallbackClient(providers)
+	}
+	return a, nil
+}
+
+// configureAIProvider configures the client of the named provider.
+func configureAIProvider(configAI ai.AIConfiguration, name string, customHeaders []http.Header) (ai.IAI, ai.AIProvider, error) {
 	var aiProvider ai.AIProvider
 	for _, provider := range configAI.Providers {
-		if backend == provider.Name {
+		if name == provider.Name {
 			aiProvider = provider
 			break
 		}
 	}
 
 	if aiProvider.Name == "" {
-		return nil, fmt.Errorf("AI provider %s not specified in configuration. Please run k8sgpt auth", backend)
+		return nil, aiProvider, fmt.Errorf("AI provider %s not specified in configuration. Please run k8sgpt auth", name)
+	}
+
+	password, err := ai.ResolvePassword(context.Background(), aiProvider.Password, clusterSecret)
+	if err != nil {
+		return nil, aiProvider, fmt.Errorf("resolving the password of AI provider %s: %w", name, err)
 	}
+	aiProvider.Password = password
 
 	aiClient := ai.NewClient(aiProvider.Name)
-	customHeaders := util.NewHeaders(httpHeaders)
 	aiProvider.CustomHeaders = customHeaders
 	if err := aiClient.Configure(&aiProvider); err != nil {
-		return nil, err
+		return nil, aiProvider, err
 	}
-	a.AIClient = aiClient
-	a.AnalysisAIProvider = aiProvider.Name
-	return a, nil
+	return aiClient, aiProvider, nil
+}
+
+// clusterSecret reads the Secrets of the k8s: password references from the
+// cluster of the current context, also when analyzing other clusters or a
+// snapshot.
+func clusterSecret(ctx context.Context, namespace, name, key string) (string, error) {
+	client, err := kubernetes.NewClient(viper.GetString("kubecontext"), viper.GetString("kubeconfig"))
+	if err != nil {
+		return "", err
+	}
+	return client.SecretValue(ctx, namespace, name, key)
 }
 
 func (a *Analysis) CustomAnalyzersAreAvailable() bool {
@@ -207,6 +347,8 @@ func (a *Analysis) RunCustomAnalysis() {
 				a.Errors = append(a.Errors, fmt.Sprintf("[%s] %s", cAnalyzer.Name, err))
 				mutex.Unlock()
 			} else {
+				result.Analyzer = cAnalyzer.Name
+				result.SetFingerprints()
 				mutex.Lock()
 				a.Results = append(a.Results, result)
 				mutex.Unlock()
@@ -218,68 +360,152 @@ func (a *Analysis) RunCustomAnalysis() {
 }
 
 func (a *Analysis) RunAnalysis() {
-	activeFilters := viper.GetStringSlice("active_filters")
-
-	coreAnalyzerMap, analyzerMap := analyzer.GetAnalyzerMap()
+	analyzers := a.selectedAnalyzers()
+	if len(a.clusters) > 0 {
+		a.runClusterAnalysis(analyzers)
+	} else {
+		a.runAnalyzers(analyzers, make(chan struct{}, max(a.MaxConcurrency, 1)))
+	}
 
-	// we get the openapi schema from the server only if required by the flag "with-doc"
-	openapiSchema := &openapi_v2.Document{}
-	if a.WithDoc {
-		var openApiErr error
+	var suppressed int
+	a.Results, suppressed = a.suppressions.apply(a.Results)
+	a.Suppressed += suppressed
+}
 
-		openapiSchema, openApiErr = a.Client.Client.Discovery().OpenAPISchema()
-		if openApiErr != nil {
-			a.Errors = append(a.Errors, fmt.Sprintf("[KubernetesDoc] %s", openApiErr))
-		}
+// LoadSuppressions replaces the suppressions with the ones of the file.
+// Expired suppressions are reported as errors of the analysis.
+func (a *Analysis) LoadSuppressions(file string) error {
+	suppressions, warnings, err := loadSuppressions(file, time.Now())
+	if err != nil {
+		return err
 	}
+	a.suppressions = suppressions
+	a.Errors = append(a.Errors, warnings...)
+	return nil
+}
 
-	analyzerConfig := common.Analyzer{
-		Client:        a.Client,
-		Context:       a.Context,
-		Namespace:     a.Namespace,
-		LabelSelector: a.LabelSelector,
-		AIClient:      a.AIClient,
-		OpenapiSchema: openapiSchema,
-	}
+// clusterScopedAnalyzers are the analyzers of cluster scoped objects, which
+// report the same results for every namespace.
+var clusterScopedAnalyzers = map[string]bool{
+	"Node":                           true,
+	"ValidatingWebhookConfiguration": true,
+	"MutatingWebhookConfiguration":   true,
+	"GatewayClass":                   true,
+}
+
+// runAnalyzers runs the analyzers against every selected namespace of the
+// cluster of a.Client, and the cluster scoped ones once. The semaphore
+// bounds the analyzers running at once.
+func (a *Analysis) runAnalyzers(analyzers map[string]common.IAnalyzer, semaphore chan struct{}) {
+	analyzerConfig := a.analyzerConfig()
+	namespaces := a.namespaces()
 
-	semaphore := make(chan struct{}, a.MaxConcurrency)
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
-	// if there are no filters selected and no active_filters then run coreAnalyzer
-	if len(a.Filters) == 0 && len(activeFilters) == 0 {
-		for name, analyzer := range coreAnalyzerMap {
+	for i, namespace := range namespaces {
+		namespaceConfig := analyzerConfig
+		namespaceConfig.Namespace = namespace
+		for name, analyzer := range analyzers {
+			if i > 0 && clusterScopedAnalyzers[name] {
+				continue
+			}
 			wg.Add(1)
 			semaphore <- struct{}{}
-			go a.executeAnalyzer(analyzer, name, analyzerConfig, semaphore, &wg, &mutex)
+			go a.executeAnalyzer(analyzer, name, namespaceConfig, semaphore, &wg, &mutex)
+		}
+	}
+	wg.Wait()
+
+	if len(namespaces) > 1 {
+		// Analyzers listing their objects in every namespace, e.g. Gateway,
+		// report the same results for every namespace.
+		a.Results = uniqueResults(a.Results)
+	}
+	a.Results = a.exclusions.filter(a.Context, a.Results, a.MaxConcurrency)
+}
 
+// namespaces returns the comma separated namespaces of a.Namespace, or the
+// empty namespace to analyze all of them.
+func (a *Analysis) namespaces() []string {
+	var namespaces []string
+	for _, namespace := range strings.Split(a.Namespace, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		return []string{""}
+	}
+	return namespaces
+}
+
+func uniqueResults(results []common.Result) []common.Result {
+	seen := map[string]bool{}
+	unique := results[:0]
+	for _, result := range results {
+		key := result.Analyzer + "/" + result.Kind + "/" + result.Name
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, result)
 		}
-		wg.Wait()
-		return
 	}
+	return unique
+}
+
+// selectedAnalyzers returns the analyzers to run, keyed by filter name. If
+// there are no filters selected and no active_filters, only the core
+// analyzers run.
+func (a *Analysis) selectedAnalyzers() map[string]common.IAnalyzer {
+	activeFilters := viper.GetStringSlice("active_filters")
+
+	coreAnalyzerMap, analyzerMap := analyzer.GetAnalyzerMap()
+
+	if len(a.Filters) == 0 && len(activeFilters) == 0 {
+		return coreAnalyzerMap
+	}
+
+	selected := map[string]common.IAnalyzer{}
 	// if the filters flag is specified
 	if len(a.Filters) != 0 {
 		for _, filter := range a.Filters {
 			if analyzer, ok := analyzerMap[filter]; ok {
-				semaphore <- struct{}{}
-				wg.Add(1)
-				go a.executeAnalyzer(analyzer, filter, analyzerConfig, semaphore, &wg, &mutex)
+				selected[filter] = analyzer
 			} else {
 				a.Errors = append(a.Errors, fmt.Sprintf("\"%s\" filter does not exist. Please run k8sgpt filters list.", filter))
 			}
 		}
-		wg.Wait()
-		return
+		return selected
 	}
 
 	// use active_filters
 	for _, filter := range activeFilters {
 		if analyzer, ok := analyzerMap[filter]; ok {
-			semaphore <- struct{}{}
-			wg.Add(1)
-			go a.executeAnalyzer(analyzer, filter, analyzerConfig, semaphore, &wg, &mutex)
+			selected[filter] = analyzer
 		}
 	}
-	wg.Wait()
+	return selected
+}
+
+func (a *Analysis) analyzerConfig() common.Analyzer {
+	// we get the openapi schema from the server only if required by the flag "with-doc"
+	openapiSchema := &openapi_v2.Document{}
+	if a.WithDoc {
+		var openApiErr error
+
+		openapiSchema, openApiErr = a.Client.Client.Discovery().OpenAPISchema()
+		if openApiErr != nil {
+			a.Errors = append(a.Errors, fmt.Sprintf("[KubernetesDoc] %s", openApiErr))
+		}
+	}
+
+	return common.Analyzer{
+		Client:        a.Client,
+		Context:       a.Context,
+		Namespace:     a.Namespace,
+		LabelSelector: a.LabelSelector,
+		AIClient:      a.AIClient,
+		OpenapiSchema: openapiSchema,
+	}
 }
 
 func (a *Analysis) executeAnalyzer(analyzer common.IAnalyzer, filter string, analyzerConfig common.Analyzer, semaphore chan struct{}, wg *sync.WaitGroup, mutex *sync.Mutex) {
@@ -298,6 +524,10 @@ func (a *Analysis) executeAnalyzer(analyzer common.IAnalyzer, filter string, ana
 	if err != nil {
 		fmt.Println(err)
 	}
+	for i := range results {
+		results[i].Analyzer = filter
+		results[i].SetFingerprints()
+	}
 	// Measure the time taken
 	if a.WithStats {
 		elapsedTime = time.Since(startTime)
@@ -310,115 +540,346 @@ func (a *Analysis) executeAnalyzer(analyzer common.IAnalyzer, filter string, ana
 	mutex.Lock()
 	defer mutex.Unlock()
 
+	if a.WithStats {
+		a.addStat(stat)
+	}
 	if err != nil {
-		if a.WithStats {
-			a.Stats = append(a.Stats, stat)
-		}
 		a.Errors = append(a.Errors, fmt.Sprintf("[%s] %s", filter, err))
 	} else {
-		if a.WithStats {
-			a.Stats = append(a.Stats, stat)
-		}
 		a.Results = append(a.Results, results...)
 	}
 	<-semaphore
 }
 
+// addStat records the duration of an analyzer, adding up the durations of
+// the namespaces it ran against.
+func (a *Analysis) addStat(stat common.AnalysisStats) {
+	for i := range a.Stats {
+		if a.Stats[i].Analyzer == stat.Analyzer {
+			a.Stats[i].DurationTime += stat.DurationTime
+			return
+		}
+	}
+	a.Stats = append(a.Stats, stat)
+}
+
 func (a *Analysis) GetAIResults(output string, anonymize bool) error {
 	if len(a.Results) == 0 {
 		return nil
 	}
 
+	// Only show the progress bar for the human readable output.
 	var bar *progressbar.ProgressBar
-	if output != "json" {
+	if output == "text" {
 		bar = progressbar.Default(int64(len(a.Results)))
 	}
 
-	for index, analysis := range a.Results {
-		var texts []string
+	if a.usage == nil {
+		a.usage = newAIUsage()
+	}
+	a.prioritize()
+	a.structured = output == "json"
 
-		for _, failure := range analysis.Error {
-			if anonymize {
-				for _, s := range failure.Sensitive {
-					failure.Text = util.ReplaceIfMatch(failure.Text, s.Unmasked, s.Masked)
-				}
+	errs := make([]error, len(a.Results))
+	if a.Batch {
+		a.explainBatches(anonymize, errs, bar)
+	} else {
+		var indexes []int
+		for index := range a.Results {
+			indexes = append(indexes, index)
+		}
+		a.explainEach(indexes, anonymize, errs, bar)
+	}
+
+	return a.explainErrors(errs)
+}
+
+// explainEach explains the results at indexes one by one, up to
+// MaxConcurrency at once. Each result is written to its own index, and its
+// error to the same index of errs.
+func (a *Analysis) explainEach(indexes []int, anonymize bool, errs []error, bar *progressbar.ProgressBar) {
+	semaphore := make(chan struct{}, max(a.MaxConcurrency, 1))
+	var wg sync.WaitGroup
+	for _, index := range indexes {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(index int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			result, provider, err := a.explainResult(a.Results[index], anonymize, nil)
+			errs[index] = a.setAIResult(index, result, provider, err)
+			if bar != nil {
+				_ = bar.Add(1)
 			}
-			texts = append(texts, failure.Text)
+		}(index)
+	}
+	wg.Wait()
+}
+
+// setAIResult records the explanation of a result, and returns its error.
+func (a *Analysis) setAIResult(index int, details, provider string, err error) error {
+	if errors.Is(err, errBudgetExhausted) {
+		a.Results[index].Details = NotExplainedBudgetExhausted
+	} else if err == nil {
+		a.Results[index].Details = details
+		a.Results[index].Provider = provider
+		if a.structured {
+			a.Results[index].Explanation, a.Results[index].Details = parseExplanation(details)
 		}
+	}
+	return err
+}
 
-		promptTemplate := ai.PromptMap["default"]
-		// If the resource `Kind` comes from an "integration plugin",
-		// maybe a customized prompt template will be involved.
-		if prompt, ok := ai.PromptMap[analysis.Kind]; ok {
-			promptTemplate = prompt
+// explainErrors records the results that could not be explained as warnings,
+// keeping the explanations of the others. It only fails if no result could
+// be explained, for another reason than the budget being exhausted or the
+// failures being unknown to the kb backend.
+func (a *Analysis) explainErrors(errs []error) error {
+	var failed, overBudget, unknown int
+	var budgetErr, lastErr error
+	for index, err := range errs {
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, errBudgetExhausted) {
+			overBudget++
+			budgetErr = err
+			continue
+		}
+		if errors.Is(err, ai.ErrNoKBEntry) {
+			unknown++
+			continue
+		}
+		failed++
+		lastErr = err
+		result := a.Results[index]
+		a.Errors = append(a.Errors, fmt.Sprintf("failed to explain %s %s: %v", result.Kind, result.Name, err))
+	}
+	if overBudget > 0 {
+		a.Errors = append(a.Errors, fmt.Sprintf("%d results not explained: %v", overBudget, budgetErr))
+	}
+	if unknown > 0 {
+		a.Errors = append(a.Errors, fmt.Sprintf("%d results not explained: %v", unknown, ai.ErrNoKBEntry))
+	}
+	if failed == 0 || failed < len(errs)-overBudget-unknown {
+		return nil
+	}
+
+	// Check for exhaustion.
+	if ai.IsRateLimited(lastErr) {
+		return fmt.Errorf("exhausted API quota for AI provider %s: %v", a.AIClient.GetName(), lastErr)
+	}
+	return fmt.Errorf("failed while calling AI provider %s: %v", a.AIClient.GetName(), lastErr)
+}
+
+// StreamAIResults prints the text output to w, streaming the explanation of
+// every result as the AI backend generates it.
+func (a *Analysis) StreamAIResults(w io.Writer, anonymize bool) error {
+	var header strings.Builder
+	hasResults := a.writeTextHeader(&header)
+	if _, err := io.WriteString(w, header.String()); err != nil || !hasResults {
+		return err
+	}
+
+	if a.usage == nil {
+		a.usage = newAIUsage()
+	}
+
+	a.prioritize()
+	details := color.New(color.FgGreen)
+	errs := make([]error, len(a.Results))
+	for index, analysis := range a.Results {
+		var output strings.Builder
+		writeTextResult(&output, index, analysis)
+		if _, err := io.WriteString(w, output.String()); err != nil {
+			return err
 		}
-		result, err := a.getAIResultForSanitizedFailures(texts, promptTemplate)
-		if err != nil {
-			// FIXME: can we avoid checking if output is json multiple times?
-			//   maybe implement the progress bar better?
-			if output != "json" {
-				_ = bar.Exit()
-			}
 
-			// Check for exhaustion.
-			if strings.Contains(err.Error(), "status code: 429") {
-				return fmt.Errorf("exhausted API quota for AI provider %s: %v", a.AIClient.GetName(), err)
+		result, provider, err := a.explainResult(analysis, anonymize, writerFunc(func(p []byte) (int, error) {
+			if _, err := details.Fprint(w, string(p)); err != nil {
+				return 0, err
 			}
-			return fmt.Errorf("failed while calling AI provider %s: %v", a.AIClient.GetName(), err)
+			return len(p), nil
+		}))
+		if errors.Is(err, errBudgetExhausted) {
+			errs[index] = err
+			a.Results[index].Details = NotExplainedBudgetExhausted
+			fmt.Fprint(w, color.YellowString(NotExplainedBudgetExhausted))
+		} else if errors.Is(err, ai.ErrNoKBEntry) {
+			errs[index] = err
+			fmt.Fprint(w, color.YellowString("Not explained: %v", err))
+		} else if err != nil {
+			errs[index] = err
+			fmt.Fprint(w, color.RedString("Error: %v", err))
+		} else {
+			a.Results[index].Details = result
+			a.Results[index].Provider = provider
 		}
+		if _, err := fmt.Fprint(w, "\n", a.fallbackNote(a.Results[index])); err != nil {
+			return err
+		}
+	}
+
+	var usage strings.Builder
+	a.writeUsage(&usage)
+	if _, err := io.WriteString(w, usage.String()); err != nil {
+		return err
+	}
+	return a.explainErrors(errs)
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+// explainResult asks the AI backend to explain the failures of a single result.
+// The explanation is streamed to w, if not nil. It returns the explanation and
+// the provider that generated it.
+func (a *Analysis) explainResult(analysis common.Result, anonymize bool, w io.Writer) (string, string, error) {
+	texts := failureTexts(analysis, anonymize)
+
+	var result, provider string
+	var err error
+	if tmpl := a.prompts.Find(analysis.Kind, analysis.Analyzer, a.Language); tmpl != nil {
+		var prompt string
+		if prompt, err = a.renderPrompt(tmpl, analysis, texts, anonymize); err != nil {
+			return "", "", fmt.Errorf("rendering prompt %s: %w", tmpl.Config.Selector(), err)
+		}
+		// The rendered prompt identifies the explanation in the cache, as
+		// it may contain more than the failures.
+		prompt, cacheInput := a.withStructuredPrompt(prompt, prompt)
+		result, provider, err = a.getAIResult(strings.Join(texts, " "), cacheInput, prompt, w)
+	} else {
+		// If the resource `Kind` comes from an "integration plugin",
+		// maybe a customized prompt template will be involved.
+		result, provider, err = a.getAIResultForSanitizedFailures(texts, ai.BuiltinPrompt(analysis.Kind), w)
+	}
+	if err != nil {
+		return "", "", err
+	}
 
+	if anonymize {
+		result = unmask(analysis, result)
+	}
+	return result, provider, nil
+}
+
+// failureTexts returns the failures of a result, masking their sensitive
+// values when anonymizing.
+func failureTexts(result common.Result, anonymize bool) []string {
+	var texts []string
+	for _, failure := range result.Error {
 		if anonymize {
-			for _, failure := range analysis.Error {
-				for _, s := range failure.Sensitive {
-					result = strings.ReplaceAll(result, s.Masked, s.Unmasked)
-				}
+			for _, s := range failure.Sensitive {
+				failure.Text = util.ReplaceIfMatch(failure.Text, s.Unmasked, s.Masked)
 			}
 		}
+		texts = append(texts, failure.Text)
+	}
+	return texts
+}
 
-		analysis.Details = result
-		if output != "json" {
-			_ = bar.Add(1)
+// unmask restores the sensitive values of a result in its explanation.
+func unmask(result common.Result, text string) string {
+	for _, failure := range result.Error {
+		for _, s := range failure.Sensitive {
+			text = strings.ReplaceAll(text, s.Masked, s.Unmasked)
 		}
-		a.Results[index] = analysis
 	}
-	return nil
+	return text
 }
 
-func (a *Analysis) getAIResultForSanitizedFailures(texts []string, promptTmpl string) (string, error) {
+func (a *Analysis) getAIResultForSanitizedFailures(texts []string, promptTmpl string, w io.Writer) (string, string, error) {
 	inputKey := strings.Join(texts, " ")
-	// Check for cached data.
-	// TODO(bwplotka): This might depend on model too (or even other client configuration pieces), fix it in later PRs.
-	cacheKey := util.GetCacheKey(a.AIClient.GetName(), a.Language, inputKey)
+	prompt, cacheInput := a.withStructuredPrompt(fmt.Sprintf(strings.TrimSpace(promptTmpl), a.Language, inputKey), inputKey)
+	return a.getAIResult(inputKey, cacheInput, prompt, w)
+}
+
+// getAIResult sends prompt, built from the failures in inputKey, to the AI
+// backend unless cacheInput was explained before.
+func (a *Analysis) getAIResult(inputKey, cacheInput, prompt string, w io.Writer) (string, string, error) {
+	if output, provider, found, err := a.cachedAIResult(cacheInput); err != nil {
+		return "", "", err
+	} else if found {
+		if w != nil {
+			_, _ = io.WriteString(w, output)
+		}
+		return output, provider, nil
+	}
+
+	completion, err := a.complete(inputKey, prompt, w)
+	if err != nil {
+		return "", "", err
+	}
+	a.storeAIResult(completion.Provider, cacheInput, completion.Text)
+	return completion.Text, completion.Provider, nil
+}
 
-	if !a.Cache.IsCacheDisabled() && a.Cache.Exists(cacheKey) {
+// cachedAIResult returns the cached answer to cacheInput, of any provider that
+// may be asked, and the provider that gave it.
+func (a *Analysis) cachedAIResult(cacheInput string) (string, string, bool, error) {
+	// TODO(bwplotka): This might depend on model too (or even other client configuration pieces), fix it in later PRs.
+	for _, provider := range ai.ProviderNames(a.AIClient) {
+		cacheKey := util.GetCacheKey(provider, a.Language, cacheInput)
+		if a.Cache.IsCacheDisabled() || !a.Cache.Exists(cacheKey) {
+			continue
+		}
 		response, err := a.Cache.Load(cacheKey)
 		if err != nil {
-			return "", err
+			return "", "", false, err
 		}
 
 		if response != "" {
 			output, err := base64.StdEncoding.DecodeString(response)
 			if err == nil {
-				return string(output), nil
+				return string(output), provider, true, nil
 			}
 			color.Red("error decoding cached data; ignoring cache item: %v", err)
 		}
 	}
+	return "", "", false, nil
+}
 
-	// Process template.
-	prompt := fmt.Sprintf(strings.TrimSpace(promptTmpl), a.Language, inputKey)
-	if a.AIClient.GetName() == ai.CustomRestClientName {
-		prompt = fmt.Sprintf(ai.PromptMap["raw"], a.Language, inputKey, prompt)
+// storeAIResult caches the answer of provider to cacheInput.
+func (a *Analysis) storeAIResult(provider, cacheInput, output string) {
+	cacheKey := util.GetCacheKey(provider, a.Language, cacheInput)
+	if err := a.Cache.Store(cacheKey, base64.StdEncoding.EncodeToString([]byte(output))); err != nil {
+		color.Red("error storing value to cache; value won't be cached: %v", err)
 	}
-	response, err := a.AIClient.GetCompletion(a.Context, prompt)
+}
+
+// complete sends prompt, built from the failures in inputKey, to the AI
+// backend within the rate limit and budget, and records its usage.
+func (a *Analysis) complete(inputKey, prompt string, w io.Writer) (ai.CompletionResult, error) {
+	ctx := a.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = ai.WithLanguage(ai.WithFailures(ctx, inputKey), a.Language)
+	// The tokens of the prompt and the longest completion are reserved.
+	estimate := ai.EstimateTokens(prompt) + a.maxTokens
+	if err := a.budget.reserve(estimate); err != nil {
+		return ai.CompletionResult{}, err
+	}
+	if a.rateLimiter != nil {
+		if err := a.rateLimiter.Wait(ctx); err != nil {
+			a.budget.release(estimate)
+			return ai.CompletionResult{}, err
+		}
+	}
+	completion, err := ai.Completion(ctx, a.AIClient, prompt, w)
 	if err != nil {
-		return "", err
+		a.budget.release(estimate)
+		return ai.CompletionResult{}, err
 	}
-
-	if err = a.Cache.Store(cacheKey, base64.StdEncoding.EncodeToString([]byte(response))); err != nil {
-		color.Red("error storing value to cache; value won't be cached: %v", err)
+	a.recordUsage(completion)
+	if err := a.budget.add(estimate, completion.Usage); err != nil {
+		color.Red("error storing AI budget usage: %v", err)
 	}
-	return response, nil
+	return completion, nil
 }
 
 func (a *Analysis) Close() {
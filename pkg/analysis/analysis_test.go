@@ -16,7 +16,11 @@ package analysis
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -393,7 +397,7 @@ func TestGetAIResultForSanitizedFailures(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			output, err := tt.a.getAIResultForSanitizedFailures(tt.texts, tt.promptTmpl)
+			output, _, err := tt.a.getAIResultForSanitizedFailures(tt.texts, tt.promptTmpl, nil)
 			if tt.expectedErr == "" {
 				require.NoError(t, err)
 				require.Equal(t, tt.expectedOutput, output)
@@ -404,3 +408,148 @@ func TestGetAIResultForSanitizedFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalysis_RunAnalysisFromSnapshot(t *testing.T) {
+	snapshot := filepath.Join(t.TempDir(), "snapshot.yaml")
+	err := os.WriteFile(snapshot, []byte(`apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: example
+    namespace: default
+  status:
+    phase: Pending
+    conditions:
+    - type: PodScheduled
+      reason: Unschedulable
+      message: "0/1 nodes are available: 1 node(s) had taint {node-role.kubernetes.io/master: }, that the pod didn't tolerate."
+`), 0600)
+	require.NoError(t, err)
+
+	client, err := kubernetes.NewClientFromSnapshot(snapshot)
+	require.NoError(t, err)
+
+	analysis := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Pod"},
+		Namespace:      "default",
+		MaxConcurrency: 1,
+		Client:         client,
+	}
+	analysis.RunAnalysis()
+	require.Len(t, analysis.Results, 1)
+	require.Equal(t, "default/example", analysis.Results[0].Name)
+}
+
+type streamingAIClient struct {
+	ai.NoOpAIClient
+	chunks []string
+}
+
+func (c *streamingAIClient) StreamCompletion(_ context.Context, _ string, w io.Writer) (string, error) {
+	for _, chunk := range c.chunks {
+		if _, err := io.WriteString(w, chunk); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(c.chunks, ""), nil
+}
+
+func TestStreamAIResults(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := Analysis{
+		AIClient: &streamingAIClient{chunks: []string{"The image ", "does not exist."}},
+		Cache:    disabledCache,
+		Explain:  true,
+		Results: []common.Result{
+			{
+				Kind:  "Pod",
+				Name:  "default/example",
+				Error: []common.Failure{{Text: "Back-off pulling image \"example:latest\""}},
+			},
+		},
+	}
+	require.True(t, ai.CanStream(a.AIClient))
+
+	var output strings.Builder
+	require.NoError(t, a.StreamAIResults(&output, false))
+	require.Equal(t, "The image does not exist.", a.Results[0].Details)
+	require.Contains(t, output.String(), "0: Pod default/example()")
+	require.Contains(t, output.String(), "Back-off pulling image \"example:latest\"\nThe image does not exist.\n")
+}
+
+type failingAIClient struct {
+	ai.NoOpAIClient
+	fail string
+}
+
+func (c *failingAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	if strings.Contains(prompt, c.fail) {
+		return "", errors.New("error, status code: 429, message: quota exceeded")
+	}
+	return c.NoOpAIClient.GetCompletion(ctx, prompt)
+}
+
+func TestGetAIResults_Partial(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	var results []common.Result
+	for i := 0; i < 20; i++ {
+		results = append(results, common.Result{
+			Kind:  "Pod",
+			Name:  fmt.Sprintf("default/pod-%d", i),
+			Error: []common.Failure{{Text: fmt.Sprintf("failure of pod-%d.", i)}},
+		})
+	}
+	a := Analysis{
+		Context:        context.Background(),
+		AIClient:       &failingAIClient{fail: "pod-7."},
+		Cache:          disabledCache,
+		MaxConcurrency: 4,
+		Results:        results,
+	}
+
+	require.NoError(t, a.GetAIResults("json", false))
+	for i, result := range a.Results {
+		if i == 7 {
+			require.Empty(t, result.Details)
+		} else {
+			require.Contains(t, result.Details, fmt.Sprintf("failure of pod-%d.", i))
+			require.Equal(t, "noopai", result.Provider)
+		}
+	}
+	require.Equal(t, []string{"failed to explain Pod default/pod-7: error, status code: 429, message: quota exceeded"}, a.Errors)
+
+	// The analysis fails if nothing could be explained.
+	a = Analysis{
+		Context:  context.Background(),
+		AIClient: &failingAIClient{fail: "failure"},
+		Cache:    disabledCache,
+		Results:  results[:2],
+	}
+	require.ErrorContains(t, a.GetAIResults("json", false), "exhausted API quota for AI provider noopai")
+	require.Len(t, a.Errors, 2)
+}
+
+func TestGetAIResults_UnknownToKB(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	client := &ai.KBClient{}
+	require.NoError(t, client.Configure(&ai.AIProvider{Name: "kb"}))
+	a := Analysis{
+		Context:  context.Background(),
+		AIClient: client,
+		Cache:    disabledCache,
+		Results: []common.Result{
+			{Kind: "Deployment", Name: "default/web", Error: []common.Failure{{Text: "Deployment default/web has 1 replicas but 2 are available"}}},
+			{Kind: "Deployment", Name: "default/api", Error: []common.Failure{{Text: "Deployment default/api has 1 replicas but 3 are available"}}},
+		},
+	}
+
+	// Failures unknown to the knowledge base are warnings, not errors.
+	require.NoError(t, a.GetAIResults("json", false))
+	require.Equal(t, []string{"2 results not explained: no known failure signature matches"}, a.Errors)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/schollz/progressbar/v3"
+)
+
+const (
+	// defaultContextWindow is the context window of the models that don't
+	// configure one, in tokens.
+	defaultContextWindow = 8192
+	// batchAnswerTokens are the tokens left for the answer to each failure.
+	batchAnswerTokens = 150
+)
+
+// batchItem is a result to explain in a batch, with the other results of the
+// same failures.
+type batchItem struct {
+	index      int
+	duplicates []int
+	id         string
+	inputKey   string
+	cacheInput string
+}
+
+// explainBatches explains the results using the default prompt several at
+// once, with prompts filling up to half of the model's context window and
+// leaving the other half to the answers. Results of the same failures are
+// sent once, and their answer is given to each of them. The answers are
+// cached per failures, as if the results were explained one by one. The
+// results with a prompt of their own, alone in their batch or missing from
+// the answers are explained one by one instead.
+func (a *Analysis) explainBatches(anonymize bool, errs []error, bar *progressbar.ProgressBar) {
+	var single []int
+	var items []batchItem
+	groups := map[string]int{}
+	for index, result := range a.Results {
+		if _, ok := ai.PromptMap[result.Kind]; ok || a.prompts.Find(result.Kind, result.Analyzer, a.Language) != nil {
+			single = append(single, index)
+			continue
+		}
+		inputKey := strings.Join(failureTexts(result, anonymize), " ")
+		_, cacheInput := a.withStructuredPrompt("", inputKey)
+		if group, ok := groups[cacheInput]; ok {
+			items[group].duplicates = append(items[group].duplicates, index)
+			continue
+		}
+		details, provider, found, err := a.cachedAIResult(cacheInput)
+		if err != nil || found {
+			if anonymize {
+				details = unmask(result, details)
+			}
+			errs[index] = a.setAIResult(index, details, provider, err)
+			if bar != nil {
+				_ = bar.Add(1)
+			}
+			continue
+		}
+		groups[cacheInput] = len(items)
+		items = append(items, batchItem{
+			index:      index,
+			id:         strconv.Itoa(len(items) + 1),
+			inputKey:   inputKey,
+			cacheInput: cacheInput,
+		})
+	}
+
+	var mutex sync.Mutex
+	var missing []batchItem
+	semaphore := make(chan struct{}, max(a.MaxConcurrency, 1))
+	var wg sync.WaitGroup
+	for _, batch := range a.batches(items) {
+		if len(batch) == 1 {
+			missing = append(missing, batch[0])
+			continue
+		}
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(batch []batchItem) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			batchMissing := a.explainBatch(batch, anonymize, errs, bar)
+			mutex.Lock()
+			missing = append(missing, batchMissing...)
+			mutex.Unlock()
+		}(batch)
+	}
+	wg.Wait()
+
+	for _, item := range missing {
+		single = append(single, item.index)
+	}
+	a.explainEach(single, anonymize, errs, bar)
+	for _, item := range missing {
+		for _, index := range item.duplicates {
+			a.Results[index].Details = a.Results[item.index].Details
+			a.Results[index].Explanation = a.Results[item.index].Explanation
+			a.Results[index].Provider = a.Results[item.index].Provider
+			errs[index] = errs[item.index]
+			if bar != nil {
+				_ = bar.Add(1)
+			}
+		}
+	}
+}
+
+// ContextWindow returns the number of tokens of the model's context window.
+func (a *Analysis) ContextWindow() int {
+	if a.contextWindow <= 0 {
+		return defaultContextWindow
+	}
+	return a.contextWindow
+}
+
+// batches splits the items into batches fitting the model's context window.
+func (a *Analysis) batches(items []batchItem) [][]batchItem {
+	window := a.ContextWindow()
+	maxTokens := window/2 - ai.EstimateTokens(ai.BatchPrompt+ai.BatchStructuredAnswer)
+	maxItems := max(window/2/batchAnswerTokens, 1)
+
+	var batches [][]batchItem
+	var batch []batchItem
+	tokens := 0
+	for _, item := range items {
+		// Count the ID and delimiters of the item too.
+		itemTokens := ai.EstimateTokens(item.inputKey) + 8
+		if len(batch) > 0 && (tokens+itemTokens > maxTokens || len(batch) == maxItems) {
+			batches = append(batches, batch)
+			batch, tokens = nil, 0
+		}
+		batch = append(batch, item)
+		tokens += itemTokens
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// explainBatch explains a batch of results with a single prompt, and returns
+// the items missing from the answers.
+func (a *Analysis) explainBatch(batch []batchItem, anonymize bool, errs []error, bar *progressbar.ProgressBar) []batchItem {
+	var failures strings.Builder
+	var inputKeys []string
+	for _, item := range batch {
+		fmt.Fprintf(&failures, "ID %s: --- %s ---\n", item.id, item.inputKey)
+		inputKeys = append(inputKeys, item.inputKey)
+	}
+	answer := ai.BatchAnswer
+	if a.structured {
+		answer = ai.BatchStructuredAnswer
+	}
+	prompt := fmt.Sprintf(ai.BatchPrompt, a.Language, strings.TrimSpace(failures.String()), answer)
+
+	completion, err := a.complete(strings.Join(inputKeys, " "), prompt, nil)
+	var answers map[string]string
+	if err == nil {
+		answers = parseBatchAnswers(completion.Text)
+	}
+	var missing []batchItem
+	for _, item := range batch {
+		details, ok := answers[item.id]
+		if err == nil && !ok {
+			missing = append(missing, item)
+			continue
+		}
+		if err == nil {
+			a.storeAIResult(completion.Provider, item.cacheInput, details)
+		}
+		for _, index := range append([]int{item.index}, item.duplicates...) {
+			resultDetails := details
+			if err == nil && anonymize {
+				resultDetails = unmask(a.Results[index], details)
+			}
+			errs[index] = a.setAIResult(index, resultDetails, completion.Provider, err)
+			if bar != nil {
+				_ = bar.Add(1)
+			}
+		}
+	}
+	return missing
+}
+
+// parseBatchAnswers parses the answers to a BatchPrompt by ID, possibly
+// wrapped in a markdown code block. Structured answers are kept as JSON.
+func parseBatchAnswers(text string) map[string]string {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(text[start:end+1]), &raw); err != nil {
+		return nil
+	}
+	answers := map[string]string{}
+	for id, answer := range raw {
+		var text string
+		if err := json.Unmarshal(answer, &text); err != nil {
+			text = string(answer)
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			answers[strings.TrimSpace(id)] = text
+		}
+	}
+	return answers
+}
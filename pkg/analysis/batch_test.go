@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCache is a cache.ICache in memory.
+type memoryCache struct {
+	cache.ICache
+	mutex sync.Mutex
+	data  map[string]string
+}
+
+func (c *memoryCache) Store(key string, data string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.data[key] = data
+	return nil
+}
+
+func (c *memoryCache) Load(key string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.data[key], nil
+}
+
+func (c *memoryCache) Exists(key string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	_, ok := c.data[key]
+	return ok
+}
+
+func (c *memoryCache) IsCacheDisabled() bool {
+	return false
+}
+
+var batchItemRegexp = regexp.MustCompile(`ID (\d+): --- (.*?) ---`)
+
+// batchAIClient answers the batch prompts, except for the failures
+// containing skip, and the other prompts one by one.
+type batchAIClient struct {
+	ai.NoOpAIClient
+	skip    string
+	mutex   sync.Mutex
+	batches []int
+	singles int
+}
+
+func (c *batchAIClient) GetCompletion(ctx context.Context, prompt string) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	items := batchItemRegexp.FindAllStringSubmatch(prompt, -1)
+	if len(items) == 0 {
+		c.singles++
+		return c.NoOpAIClient.GetCompletion(ctx, prompt)
+	}
+	c.batches = append(c.batches, len(items))
+	var answers []string
+	for _, item := range items {
+		if !strings.Contains(item[2], c.skip) {
+			answers = append(answers, fmt.Sprintf(`%q: "Error: %s Solution: Pull it again."`, item[1], item[2]))
+		}
+	}
+	return "```json\n{" + strings.Join(answers, ", ") + "}\n```", nil
+}
+
+func TestGetAIResults_Batch(t *testing.T) {
+	var results []common.Result
+	for i := 0; i < 30; i++ {
+		results = append(results, common.Result{
+			Kind:  "Pod",
+			Name:  fmt.Sprintf("default/pod-%d", i),
+			Error: []common.Failure{{Text: fmt.Sprintf("Back-off pulling image of pod-%d", i)}},
+		})
+	}
+	results = append(results, common.Result{
+		Kind:  "PolicyReport",
+		Name:  "default/report",
+		Error: []common.Failure{{Text: "policy violated"}},
+	})
+	memory := &memoryCache{data: map[string]string{}}
+	client := &batchAIClient{skip: "pod-3"}
+	a := Analysis{
+		Context:        context.Background(),
+		AIClient:       client,
+		Cache:          memory,
+		Batch:          true,
+		MaxConcurrency: 2,
+		contextWindow:  2000,
+		Results:        results,
+	}
+
+	require.NoError(t, a.GetAIResults("json", false))
+	// 2000 tokens fit 6 failures per batch, the failures of pod-3 missing
+	// from the answers and the PolicyReport with its own prompt are
+	// explained one by one.
+	require.Equal(t, []int{6, 6, 6, 6, 6}, client.batches)
+	require.Equal(t, 2, client.singles)
+	for i, result := range a.Results[:30] {
+		if i == 3 {
+			require.Contains(t, result.Details, "I am a noop response to the prompt")
+			continue
+		}
+		require.Equal(t, fmt.Sprintf("Error: Back-off pulling image of pod-%d Solution: Pull it again.", i), result.Details)
+		require.Equal(t, &common.Explanation{
+			Summary: fmt.Sprintf("Back-off pulling image of pod-%d", i),
+			Steps:   []string{"Pull it again."},
+		}, result.Explanation)
+		require.Equal(t, "noopai", result.Provider)
+	}
+	require.Len(t, memory.data, 31)
+
+	// The answers are cached per result.
+	client.batches, client.singles = nil, 0
+	require.NoError(t, a.GetAIResults("json", false))
+	require.Empty(t, client.batches)
+	require.Zero(t, client.singles)
+}
+
+func TestGetAIResults_BatchDuplicates(t *testing.T) {
+	var results []common.Result
+	for i := 0; i < 20; i++ {
+		results = append(results, common.Result{
+			Kind:  "Pod",
+			Name:  fmt.Sprintf("default/pod-%d", i),
+			Error: []common.Failure{{Text: fmt.Sprintf("Back-off pulling image nginx:1.%d", i%2)}},
+		})
+	}
+	memory := &memoryCache{data: map[string]string{}}
+	client := &batchAIClient{skip: "not skipped"}
+	a := Analysis{
+		Context:        context.Background(),
+		AIClient:       client,
+		Cache:          memory,
+		Batch:          true,
+		MaxConcurrency: 2,
+		contextWindow:  2000,
+		Results:        results,
+	}
+
+	// The two distinct failures are sent once, in a single batch.
+	require.NoError(t, a.GetAIResults("text", false))
+	require.Equal(t, []int{2}, client.batches)
+	require.Zero(t, client.singles)
+	for i, result := range a.Results {
+		require.Equal(t, fmt.Sprintf("Error: Back-off pulling image nginx:1.%d Solution: Pull it again.", i%2), result.Details)
+		require.Equal(t, "noopai", result.Provider)
+	}
+	require.Len(t, memory.data, 2)
+
+	// A failure alone in its batch is explained once for all its results.
+	client.batches = nil
+	a.Results = []common.Result{
+		{Kind: "Pod", Name: "default/pod-0", Error: results[0].Error},
+		{Kind: "Pod", Name: "default/pod-2", Error: results[2].Error},
+		{Kind: "Pod", Name: "default/pod-4", Error: results[4].Error},
+	}
+	a.Cache = &memoryCache{data: map[string]string{}}
+	require.NoError(t, a.GetAIResults("text", false))
+	require.Empty(t, client.batches)
+	require.Equal(t, 1, client.singles)
+	for _, result := range a.Results {
+		require.Equal(t, a.Results[0].Details, result.Details)
+		require.Contains(t, result.Details, "I am a noop response to the prompt")
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/gofrs/flock"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	"github.com/spf13/viper"
+)
+
+// NotExplainedBudgetExhausted is the details of the results left unexplained
+// once the AI budget is exhausted.
+const NotExplainedBudgetExhausted = "not explained: budget exhausted"
+
+var errBudgetExhausted = errors.New("AI budget exhausted")
+
+// Budget limits the AI calls of an analysis. Zero values are unlimited.
+type Budget struct {
+	MaxCalls        int
+	MaxTokens       int
+	MaxTokensPerDay int
+	// UsageFile persists the tokens used per day, by default in the
+	// configuration directory.
+	UsageFile string
+}
+
+// dailyUsage is the content of the usage file.
+type dailyUsage struct {
+	Date   string `json:"date"`
+	Calls  int    `json:"calls"`
+	Tokens int    `json:"tokens"`
+}
+
+type budget struct {
+	Budget
+	mutex  sync.Mutex
+	now    func() time.Time
+	calls  int
+	tokens int
+	// reserved are the tokens estimated for the calls in flight.
+	reserved int
+	daily    dailyUsage
+}
+
+// DefaultBudgetUsageFile returns the file persisting the daily AI usage, next
+// to the configuration file.
+func DefaultBudgetUsageFile() string {
+	if config := viper.ConfigFileUsed(); config != "" {
+		return filepath.Join(filepath.Dir(config), "budget.json")
+	}
+	return filepath.Join(xdg.ConfigHome, "k8sgpt", "budget.json")
+}
+
+// SetBudget limits the AI calls of the analysis. The results are then
+// explained from the most to the least severe, and the ones left when the
+// budget is exhausted are not explained.
+func (a *Analysis) SetBudget(limits Budget) error {
+	if limits.MaxCalls < 0 || limits.MaxTokens < 0 || limits.MaxTokensPerDay < 0 {
+		return errors.New("AI budget limits must not be negative")
+	}
+	if limits.MaxCalls == 0 && limits.MaxTokens == 0 && limits.MaxTokensPerDay == 0 {
+		a.budget = nil
+		return nil
+	}
+	b := &budget{Budget: limits, now: time.Now}
+	if limits.MaxTokensPerDay > 0 {
+		if b.UsageFile == "" {
+			b.UsageFile = DefaultBudgetUsageFile()
+		}
+		var err error
+		if b.daily, err = readDailyUsage(b.UsageFile); err != nil {
+			return err
+		}
+	}
+	a.budget = b
+	return nil
+}
+
+// reserve reports whether the budget allows another call, and reserves the
+// tokens estimated for it until they are settled by add or release. The
+// tokens of the calls in flight count as used, so that concurrent calls
+// cannot exceed the limits by more than a single call.
+func (b *budget) reserve(estimate int) error {
+	if b == nil {
+		return nil
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	switch {
+	case b.MaxCalls > 0 && b.calls >= b.MaxCalls:
+		return fmt.Errorf("%w: %d calls", errBudgetExhausted, b.MaxCalls)
+	case b.MaxTokens > 0 && b.tokens+b.reserved >= b.MaxTokens:
+		return fmt.Errorf("%w: %d tokens", errBudgetExhausted, b.MaxTokens)
+	case b.MaxTokensPerDay > 0 && b.today().Tokens+b.reserved >= b.MaxTokensPerDay:
+		return fmt.Errorf("%w: %d tokens per day", errBudgetExhausted, b.MaxTokensPerDay)
+	}
+	// Calls are counted when made, so that concurrent calls cannot exceed
+	// the maximum.
+	b.calls++
+	b.reserved += estimate
+	return nil
+}
+
+// release gives back the tokens reserved for a call that failed.
+func (b *budget) release(estimate int) {
+	if b == nil {
+		return
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.reserved -= estimate
+}
+
+// add settles the tokens reserved for a call with the ones it used, and
+// persists the daily usage.
+func (b *budget) add(estimate int, usage ai.Usage) error {
+	if b == nil {
+		return nil
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	tokens := usage.PromptTokens + usage.CompletionTokens
+	b.reserved -= estimate
+	b.tokens += tokens
+	if b.MaxTokensPerDay == 0 {
+		return nil
+	}
+	b.daily = b.today()
+	b.daily.Calls++
+	b.daily.Tokens += tokens
+
+	if err := os.MkdirAll(filepath.Dir(b.UsageFile), 0755); err != nil {
+		return err
+	}
+	// Other runs may have used tokens since the file was read, it is read
+	// again under a lock shared by all of them.
+	lock := flock.New(b.UsageFile + ".lock")
+	if err := lock.Lock(); err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+	daily, err := readDailyUsage(b.UsageFile)
+	if err != nil {
+		return err
+	}
+	if daily.Date == b.daily.Date {
+		b.daily.Calls = daily.Calls + 1
+		b.daily.Tokens = daily.Tokens + tokens
+	}
+
+	data, err := json.Marshal(b.daily)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(b.UsageFile, data, 0600)
+}
+
+// readDailyUsage reads the usage file, empty if it does not exist.
+func readDailyUsage(file string) (dailyUsage, error) {
+	var daily dailyUsage
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return daily, nil
+	}
+	if err != nil {
+		return daily, err
+	}
+	if err := json.Unmarshal(data, &daily); err != nil {
+		return daily, fmt.Errorf("error parsing AI budget usage file %s: %w", file, err)
+	}
+	return daily, nil
+}
+
+// today returns the usage of the current day.
+func (b *budget) today() dailyUsage {
+	date := b.now().Format(time.DateOnly)
+	if b.daily.Date != date {
+		return dailyUsage{Date: date}
+	}
+	return b.daily
+}
+
+// prioritize sorts the results from the most to the least severe when a
+// budget is set, so that the most severe ones are explained first.
+func (a *Analysis) prioritize() {
+	if a.budget == nil {
+		return
+	}
+	sort.SliceStable(a.Results, func(i, j int) bool {
+		si, sj := a.Results[i].HighestSeverity(), a.Results[j].HighestSeverity()
+		return si.AtLeast(sj) && !sj.AtLeast(si)
+	})
+}
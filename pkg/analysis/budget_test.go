@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func budgetAnalysis() *Analysis {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	return &Analysis{
+		Context:        context.Background(),
+		AIClient:       &ai.NoOpAIClient{},
+		Cache:          disabledCache,
+		MaxConcurrency: 1,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/low", Error: []common.Failure{{Text: "low problem", Severity: common.SeverityLow}}},
+			{Kind: "Pod", Name: "default/critical", Error: []common.Failure{{Text: "critical problem", Severity: common.SeverityCritical}}},
+			{Kind: "Pod", Name: "default/high", Error: []common.Failure{{Text: "high problem", Severity: common.SeverityHigh}}},
+		},
+	}
+}
+
+func TestBudget_MaxCalls(t *testing.T) {
+	a := budgetAnalysis()
+	require.NoError(t, a.SetBudget(Budget{MaxCalls: 2}))
+
+	require.NoError(t, a.GetAIResults("json", false))
+	require.Equal(t, "default/critical", a.Results[0].Name)
+	require.Contains(t, a.Results[0].Details, "critical problem")
+	require.Equal(t, "default/high", a.Results[1].Name)
+	require.Contains(t, a.Results[1].Details, "high problem")
+	require.Equal(t, "default/low", a.Results[2].Name)
+	require.Equal(t, NotExplainedBudgetExhausted, a.Results[2].Details)
+	require.Equal(t, []string{"1 results not explained: AI budget exhausted: 2 calls"}, a.Errors)
+}
+
+func TestBudget_MaxTokens(t *testing.T) {
+	a := budgetAnalysis()
+	require.NoError(t, a.SetBudget(Budget{MaxTokens: 1}))
+
+	// Nothing is explained after the first call, without failing the run.
+	require.NoError(t, a.GetAIResults("json", false))
+	require.Contains(t, a.Results[0].Details, "critical problem")
+	require.Equal(t, NotExplainedBudgetExhausted, a.Results[1].Details)
+	require.Equal(t, NotExplainedBudgetExhausted, a.Results[2].Details)
+}
+
+func TestBudget_MaxTokensPerDay(t *testing.T) {
+	usageFile := filepath.Join(t.TempDir(), "k8sgpt", "budget.json")
+	a := budgetAnalysis()
+	require.NoError(t, a.SetBudget(Budget{MaxTokensPerDay: 100000, UsageFile: usageFile}))
+	require.NoError(t, a.GetAIResults("json", false))
+	for _, result := range a.Results {
+		require.NotEqual(t, NotExplainedBudgetExhausted, result.Details)
+	}
+	tokens := a.budget.tokens
+	require.Positive(t, tokens)
+
+	// The usage of the day is shared by the next runs.
+	a = budgetAnalysis()
+	require.NoError(t, a.SetBudget(Budget{MaxTokensPerDay: tokens, UsageFile: usageFile}))
+	require.Equal(t, 3, a.budget.daily.Calls)
+	require.ErrorIs(t, a.budget.reserve(0), errBudgetExhausted)
+
+	// And reset the next day.
+	a.budget.now = func() time.Time { return time.Now().AddDate(0, 0, 1) }
+	require.NoError(t, a.budget.reserve(0))
+
+	// Runs sharing the usage file add up their usage.
+	first, second := budgetAnalysis(), budgetAnalysis()
+	require.NoError(t, first.SetBudget(Budget{MaxTokensPerDay: 100000, UsageFile: usageFile}))
+	require.NoError(t, second.SetBudget(Budget{MaxTokensPerDay: 100000, UsageFile: usageFile}))
+	require.NoError(t, first.budget.add(0, ai.Usage{PromptTokens: 10}))
+	require.NoError(t, second.budget.add(0, ai.Usage{PromptTokens: 20}))
+	require.Equal(t, tokens+30, second.budget.daily.Tokens)
+	require.Equal(t, 5, second.budget.daily.Calls)
+	entries, err := os.ReadDir(filepath.Dir(usageFile))
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "the usage file and its lock")
+
+	require.NoError(t, os.WriteFile(usageFile, []byte("not json"), 0600))
+	require.ErrorContains(t, budgetAnalysis().SetBudget(Budget{MaxTokensPerDay: 1, UsageFile: usageFile}), "error parsing AI budget usage file")
+}
+
+func TestBudget_Reserve(t *testing.T) {
+	a := budgetAnalysis()
+	require.NoError(t, a.SetBudget(Budget{MaxTokens: 1000}))
+
+	// The tokens of the calls in flight count as used.
+	require.NoError(t, a.budget.reserve(600))
+	require.NoError(t, a.budget.reserve(600))
+	require.ErrorIs(t, a.budget.reserve(600), errBudgetExhausted)
+
+	// And are settled with the tokens used.
+	a.budget.release(600)
+	require.NoError(t, a.budget.add(600, ai.Usage{PromptTokens: 100, CompletionTokens: 50}))
+	require.Equal(t, 150, a.budget.tokens)
+	require.Zero(t, a.budget.reserved)
+	require.NoError(t, a.budget.reserve(600))
+}
+
+func TestBudget_Unlimited(t *testing.T) {
+	a := budgetAnalysis()
+	require.NoError(t, a.SetBudget(Budget{}))
+	require.Nil(t, a.budget)
+	require.Error(t, a.SetBudget(Budget{MaxCalls: -1}))
+
+	// Results keep their order without budget.
+	require.NoError(t, a.GetAIResults("json", false))
+	require.Equal(t, "default/low", a.Results[0].Name)
+}
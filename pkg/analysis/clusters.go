@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+)
+
+type cluster struct {
+	// name is the kubecontext of the cluster.
+	name   string
+	client *kubernetes.Client
+}
+
+// newClusters creates a client for every kubecontext concurrently. Contexts
+// whose cluster cannot be reached are returned as errors.
+func newClusters(contexts []string, kubeconfig string, maxConcurrency int) ([]cluster, []string) {
+	clients := make([]*kubernetes.Client, len(contexts))
+	errs := make([]error, len(contexts))
+
+	semaphore := make(chan struct{}, max(maxConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, context := range contexts {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, context string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			clients[i], errs[i] = kubernetes.NewClient(context, kubeconfig)
+		}(i, context)
+	}
+	wg.Wait()
+
+	var clusters []cluster
+	var clusterErrors []string
+	for i, context := range contexts {
+		if errs[i] != nil {
+			clusterErrors = append(clusterErrors, fmt.Sprintf("[%s] initialising kubernetes client: %v", context, errs[i]))
+			continue
+		}
+		clusters = append(clusters, cluster{name: context, client: clients[i]})
+	}
+	return clusters, clusterErrors
+}
+
+// runClusterAnalysis runs the analyzers against every cluster concurrently
+// and merges their results, in the order of the clusters. The analyzers of
+// all the clusters share MaxConcurrency.
+func (a *Analysis) runClusterAnalysis(analyzers map[string]common.IAnalyzer) {
+	analyses := make([]*Analysis, len(a.clusters))
+
+	semaphore := make(chan struct{}, max(a.MaxConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, c := range a.clusters {
+		clusterAnalysis := *a
+		clusterAnalysis.Client = c.client
+		clusterAnalysis.Results = nil
+		clusterAnalysis.Errors = nil
+		clusterAnalysis.Stats = nil
+		clusterAnalysis.clusters = nil
+		clusterAnalysis.exclusions = a.exclusions.withClient(c.client)
+		analyses[i] = &clusterAnalysis
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clusterAnalysis.runAnalyzers(analyzers, semaphore)
+		}()
+	}
+	wg.Wait()
+
+	for i, clusterAnalysis := range analyses {
+		name := a.clusters[i].name
+		for _, result := range clusterAnalysis.Results {
+			result.Cluster = name
+			result.SetFingerprints()
+			a.Results = append(a.Results, result)
+		}
+		for _, err := range clusterAnalysis.Errors {
+			a.Errors = append(a.Errors, fmt.Sprintf("[%s] %s", name, err))
+		}
+		for _, stat := range clusterAnalysis.Stats {
+			stat.Analyzer = fmt.Sprintf("%s/%s", name, stat.Analyzer)
+			a.Stats = append(a.Stats, stat)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func notReadyNode(name string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionFalse}},
+		},
+	}
+}
+
+func fakeClient(objects ...runtime.Object) *kubernetes.Client {
+	return &kubernetes.Client{Client: fake.NewSimpleClientset(objects...)}
+}
+
+func TestAnalysis_RunAnalysisMultipleNamespaces(t *testing.T) {
+	analysis := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Pod", "Node"},
+		Namespace:      "a, b",
+		MaxConcurrency: 2,
+		Client:         fakeClient(notReadyNode("node-1")),
+	}
+	for _, namespace := range []string{"a", "b", "c"} {
+		pod := pendingPod("example")
+		pod.Namespace = namespace
+		_, err := analysis.Client.GetClient().CoreV1().Pods(namespace).Create(context.Background(), pod, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	analysis.RunAnalysis()
+
+	names := []string{}
+	for _, result := range analysis.Results {
+		names = append(names, result.Kind+" "+result.Name)
+	}
+	sort.Strings(names)
+	// The Node analyzer is cluster scoped, its result is reported once.
+	require.Equal(t, []string{"Node node-1", "Pod a/example", "Pod b/example"}, names)
+}
+
+func TestAnalysis_RunClusterAnalysis(t *testing.T) {
+	analysis := Analysis{
+		Context:        context.Background(),
+		Filters:        []string{"Node", "Unknown"},
+		MaxConcurrency: 2,
+		clusters: []cluster{
+			{name: "prod", client: fakeClient(notReadyNode("node-1"))},
+			{name: "staging", client: fakeClient(notReadyNode("node-1"), notReadyNode("node-2"))},
+		},
+	}
+	analysis.Client = analysis.clusters[0].client
+
+	analysis.RunAnalysis()
+
+	require.Len(t, analysis.Results, 3)
+	clusters := map[string]int{}
+	fingerprints := map[string]bool{}
+	for _, result := range analysis.Results {
+		clusters[result.Cluster]++
+		fingerprints[result.Fingerprint] = true
+	}
+	require.Equal(t, map[string]int{"prod": 1, "staging": 2}, clusters)
+	// The same node in different clusters is a different problem.
+	require.Len(t, fingerprints, 3)
+	// Errors that do not depend on the cluster are reported once.
+	require.Equal(t, []string{"\"Unknown\" filter does not exist. Please run k8sgpt filters list."}, analysis.Errors)
+}
+
+// runningAnalyzers counts the analyzers running at once.
+type runningAnalyzers struct {
+	mutex   sync.Mutex
+	running int
+	max     int
+}
+
+// countingAnalyzer records the namespaces it runs against.
+type countingAnalyzer struct {
+	running    *runningAnalyzers
+	mutex      sync.Mutex
+	namespaces []string
+}
+
+func (c *countingAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
+	c.mutex.Lock()
+	c.namespaces = append(c.namespaces, a.Namespace)
+	c.mutex.Unlock()
+
+	c.running.mutex.Lock()
+	c.running.running++
+	c.running.max = max(c.running.max, c.running.running)
+	c.running.mutex.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	c.running.mutex.Lock()
+	c.running.running--
+	c.running.mutex.Unlock()
+	return nil, nil
+}
+
+func TestAnalysis_RunAnalyzers(t *testing.T) {
+	running := &runningAnalyzers{}
+	node, pod := &countingAnalyzer{running: running}, &countingAnalyzer{running: running}
+	analysis := Analysis{
+		Context:        context.Background(),
+		Namespace:      "a,b,c",
+		MaxConcurrency: 2,
+		WithStats:      true,
+		clusters: []cluster{
+			{name: "prod", client: fakeClient()},
+			{name: "staging", client: fakeClient()},
+		},
+	}
+	analysis.runClusterAnalysis(map[string]common.IAnalyzer{"Node": node, "Pod": pod})
+
+	// Cluster scoped analyzers run once per cluster.
+	require.Equal(t, []string{"a", "a"}, node.namespaces)
+	require.Len(t, pod.namespaces, 6)
+	// The clusters share MaxConcurrency.
+	require.Equal(t, 2, running.max)
+	// The stats of an analyzer add up its namespaces.
+	var analyzers []string
+	for _, stat := range analysis.Stats {
+		analyzers = append(analyzers, stat.Analyzer)
+	}
+	sort.Strings(analyzers)
+	require.Equal(t, []string{"prod/Node", "prod/Pod", "staging/Node", "staging/Pod"}, analyzers)
+}
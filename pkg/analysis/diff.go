@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// DiffEntry is a single problem, i.e. one failure of a result.
+type DiffEntry struct {
+	Fingerprint  string          `json:"fingerprint"`
+	Kind         string          `json:"kind"`
+	Name         string          `json:"name"`
+	ParentObject string          `json:"parentObject,omitempty"`
+	Text         string          `json:"text"`
+	Severity     common.Severity `json:"severity"`
+	Details      string          `json:"details,omitempty"`
+}
+
+// DiffOutput lists the problems added, removed and unchanged between two
+// analysis runs.
+type DiffOutput struct {
+	Added     []DiffEntry `json:"added"`
+	Removed   []DiffEntry `json:"removed"`
+	Unchanged []DiffEntry `json:"unchanged"`
+}
+
+// HasRegressions reports whether the newer run found problems the older one did not.
+func (d *DiffOutput) HasRegressions() bool {
+	return len(d.Added) > 0
+}
+
+// CountAtLeast returns the number of added problems at least as severe as
+// threshold.
+func (d *DiffOutput) CountAtLeast(threshold common.Severity) int {
+	var count int
+	for _, entry := range d.Added {
+		if entry.Severity.AtLeast(threshold) {
+			count++
+		}
+	}
+	return count
+}
+
+// LoadJsonOutput reads the results of a previous `k8sgpt analyze -o json` run.
+func LoadJsonOutput(path string) (*JsonOutput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var output JsonOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("error parsing analysis results %s: %w", path, err)
+	}
+	return &output, nil
+}
+
+// DiffResults compares the problems of two analysis runs by fingerprint.
+// Fingerprints are always recomputed, so that results written by older
+// versions, without fingerprints or with fingerprints computed differently,
+// still match.
+func DiffResults(oldResults, newResults []common.Result) DiffOutput {
+	oldEntries := diffEntries(oldResults)
+	newEntries := diffEntries(newResults)
+
+	diff := DiffOutput{
+		Added:     []DiffEntry{},
+		Removed:   []DiffEntry{},
+		Unchanged: []DiffEntry{},
+	}
+	for fingerprint, entry := range newEntries {
+		if _, ok := oldEntries[fingerprint]; ok {
+			diff.Unchanged = append(diff.Unchanged, entry)
+		} else {
+			diff.Added = append(diff.Added, entry)
+		}
+	}
+	for fingerprint, entry := range oldEntries {
+		if _, ok := newEntries[fingerprint]; !ok {
+			diff.Removed = append(diff.Removed, entry)
+		}
+	}
+
+	for _, entries := range [][]DiffEntry{diff.Added, diff.Removed, diff.Unchanged} {
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Kind != entries[j].Kind {
+				return entries[i].Kind < entries[j].Kind
+			}
+			if entries[i].Name != entries[j].Name {
+				return entries[i].Name < entries[j].Name
+			}
+			return entries[i].Text < entries[j].Text
+		})
+	}
+	return diff
+}
+
+func diffEntries(results []common.Result) map[string]DiffEntry {
+	entries := map[string]DiffEntry{}
+	for _, result := range results {
+		result.Error = append([]common.Failure(nil), result.Error...)
+		result.SetFingerprints()
+		for _, failure := range result.Error {
+			entries[failure.Fingerprint] = DiffEntry{
+				Fingerprint:  failure.Fingerprint,
+				Kind:         result.Kind,
+				Name:         result.Name,
+				ParentObject: result.ParentObject,
+				Text:         failure.Text,
+				Severity:     failure.GetSeverity(),
+				Details:      result.Details,
+			}
+		}
+	}
+	return entries
+}
+
+// PrintOutput renders the diff in the given output format.
+func (d *DiffOutput) PrintOutput(format string) ([]byte, error) {
+	switch format {
+	case "json":
+		output, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling json: %v", err)
+		}
+		return output, nil
+	case "text":
+	default:
+		return nil, fmt.Errorf("unsupported output format: %s. Available format json,text", format)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%s %d, %s %d, %s %d\n",
+		color.RedString("Added:"), len(d.Added),
+		color.GreenString("Removed:"), len(d.Removed),
+		color.YellowString("Unchanged:"), len(d.Unchanged)))
+
+	sections := []struct {
+		title   string
+		entries []DiffEntry
+		colorFn func(format string, a ...interface{}) string
+		details bool
+	}{
+		{"Added problems", d.Added, color.RedString, true},
+		{"Removed problems", d.Removed, color.GreenString, false},
+	}
+	for _, section := range sections {
+		if len(section.entries) == 0 {
+			continue
+		}
+		output.WriteString("\n" + section.colorFn("%s:", section.title) + "\n")
+		for _, entry := range section.entries {
+			output.WriteString(fmt.Sprintf("- %s %s(%s): %s %s\n",
+				color.HiYellowString(entry.Kind),
+				color.YellowString(entry.Name),
+				color.CyanString(entry.ParentObject),
+				severityLabel(entry.Severity),
+				section.colorFn("%s", entry.Text)))
+			if entry.Details != "" && section.details {
+				output.WriteString(color.GreenString(entry.Details + "\n"))
+			}
+		}
+	}
+	return []byte(output.String()), nil
+}
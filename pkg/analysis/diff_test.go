@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffResults(t *testing.T) {
+	oldResults := []common.Result{
+		{
+			Kind:         "Pod",
+			Name:         "default/web-7d9f8c-abcde",
+			ParentObject: "Deployment/web",
+			Error: []common.Failure{
+				{Text: "back-off 5m0s restarting failed container=web pod=web-7d9f8c-abcde_default(0f4b1a6e-3c1d-4a5b-9e8f-1a2b3c4d5e6f)"},
+			},
+		},
+		{
+			Kind:  "Service",
+			Name:  "default/legacy",
+			Error: []common.Failure{{Text: "Service has no endpoints, expected label app=legacy"}},
+		},
+	}
+	newResults := []common.Result{
+		{
+			// The pod was replaced, but it is still the same problem.
+			Kind:         "Pod",
+			Name:         "default/web-5c6b7a-fghij",
+			ParentObject: "Deployment/web",
+			Error: []common.Failure{
+				{Text: "back-off 2m40s restarting failed container=web pod=web-5c6b7a-fghij_default(7a8b9c0d-1e2f-4a3b-8c4d-5e6f7a8b9c0d)"},
+			},
+		},
+		{
+			Kind:    "Ingress",
+			Name:    "default/web",
+			Error:   []common.Failure{{Text: "Ingress uses the ingress class nginx which does not exist."}},
+			Details: "test-solution",
+		},
+	}
+
+	diff := DiffResults(oldResults, newResults)
+	require.True(t, diff.HasRegressions())
+	require.Len(t, diff.Added, 1)
+	require.Equal(t, "Ingress", diff.Added[0].Kind)
+	require.Equal(t, "test-solution", diff.Added[0].Details)
+	require.Len(t, diff.Removed, 1)
+	require.Equal(t, "Service", diff.Removed[0].Kind)
+	require.Len(t, diff.Unchanged, 1)
+	require.Equal(t, "default/web-5c6b7a-fghij", diff.Unchanged[0].Name)
+
+	require.Equal(t, 1, diff.CountAtLeast(common.SeverityMedium))
+	require.Zero(t, diff.CountAtLeast(common.SeverityHigh))
+
+	diff = DiffResults(newResults, newResults)
+	require.False(t, diff.HasRegressions())
+	require.Empty(t, diff.Removed)
+
+	output, err := diff.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "Unchanged:")
+
+	_, err = diff.PrintOutput("unsupported")
+	require.ErrorContains(t, err, "unsupported output format")
+}
+
+func TestDiffResults_StaleFingerprint(t *testing.T) {
+	result := common.Result{
+		Kind:  "Service",
+		Name:  "default/legacy",
+		Error: []common.Failure{{Text: "Service has no endpoints, expected label app=legacy"}},
+	}
+	stale := result
+	stale.Fingerprint = "0000000000000000"
+	stale.Error = []common.Failure{{Text: result.Error[0].Text, Fingerprint: "0000000000000001"}}
+
+	diff := DiffResults([]common.Result{stale}, []common.Result{result})
+	require.Empty(t, diff.Added)
+	require.Empty(t, diff.Removed)
+	require.Len(t, diff.Unchanged, 1)
+	require.NotEqual(t, "0000000000000001", diff.Unchanged[0].Fingerprint)
+	// The stored fingerprint is left as is.
+	require.Equal(t, "0000000000000001", stale.Error[0].Fingerprint)
+}
+
+func TestLoadJsonOutput(t *testing.T) {
+	analysis := Analysis{
+		Results: []common.Result{
+			{
+				Kind:  "Deployment",
+				Name:  "test-deployment",
+				Error: []common.Failure{{Text: "test-problem"}},
+			},
+		},
+	}
+	data, err := analysis.PrintOutput("json")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "results.json")
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	output, err := LoadJsonOutput(path)
+	require.NoError(t, err)
+	require.Equal(t, analysis.Results, output.Results)
+
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0600))
+	_, err = LoadJsonOutput(path)
+	require.ErrorContains(t, err, "error parsing analysis results")
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IgnoreAnnotation opts an object, and the objects it owns, out of analysis.
+// Its value is a comma separated list of analyzers, or "*" for all of them,
+// e.g. `k8sgpt.ai/ignore: "PrivilegedContainer,RootUser"`.
+const IgnoreAnnotation = "k8sgpt.ai/ignore"
+
+// ExclusionConfig is the `exclusions` section of the configuration file.
+type ExclusionConfig struct {
+	// Namespaces are globs, e.g. "kube-*".
+	Namespaces []string            `mapstructure:"namespaces" yaml:"namespaces,omitempty"`
+	Resources  []ResourceExclusion `mapstructure:"resources" yaml:"resources,omitempty"`
+}
+
+// ResourceExclusion excludes the results matching all of its non-empty
+// fields. Kind and Name are regular expressions matching the whole kind and
+// name of the object or of its parent.
+type ResourceExclusion struct {
+	Kind      string   `mapstructure:"kind" yaml:"kind,omitempty"`
+	Name      string   `mapstructure:"name" yaml:"name,omitempty"`
+	Namespace string   `mapstructure:"namespace" yaml:"namespace,omitempty"`
+	Analyzers []string `mapstructure:"analyzers" yaml:"analyzers,omitempty"`
+}
+
+// objectKinds maps the kinds reported by the analyzers to the API types
+// holding their ignore annotation.
+var objectKinds = map[string]schema.GroupVersionKind{
+	"Pod":                            {Version: "v1", Kind: "Pod"},
+	"Service":                        {Version: "v1", Kind: "Service"},
+	"PersistentVolumeClaim":          {Version: "v1", Kind: "PersistentVolumeClaim"},
+	"Node":                           {Version: "v1", Kind: "Node"},
+	"Deployment":                     {Group: "apps", Version: "v1", Kind: "Deployment"},
+	"ReplicaSet":                     {Group: "apps", Version: "v1", Kind: "ReplicaSet"},
+	"StatefulSet":                    {Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	"DaemonSet":                      {Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	"Job":                            {Group: "batch", Version: "v1", Kind: "Job"},
+	"CronJob":                        {Group: "batch", Version: "v1", Kind: "CronJob"},
+	"Ingress":                        {Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"},
+	"NetworkPolicy":                  {Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"},
+	"HorizontalPodAutoScaler":        {Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"},
+	"PodDisruptionBudget":            {Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"},
+	"ValidatingWebhookConfiguration": {Group: "admissionregistration.k8s.io", Version: "v1", Kind: "ValidatingWebhookConfiguration"},
+	"MutatingWebhookConfiguration":   {Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfiguration"},
+	"GatewayClass":                   {Group: "gateway.networking.k8s.io", Version: "v1", Kind: "GatewayClass"},
+	"Gateway":                        {Group: "gateway.networking.k8s.io", Version: "v1", Kind: "Gateway"},
+	"HTTPRoute":                      {Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"},
+}
+
+type exclusions struct {
+	namespaces []string
+	resources  []resourceExclusion
+	client     *kubernetes.Client
+}
+
+type resourceExclusion struct {
+	kind      *regexp.Regexp
+	name      *regexp.Regexp
+	namespace string
+	analyzers map[string]bool
+}
+
+func newExclusions(config ExclusionConfig, client *kubernetes.Client) (*exclusions, error) {
+	e := &exclusions{client: client}
+	for _, namespace := range config.Namespaces {
+		if _, err := path.Match(namespace, ""); err != nil {
+			return nil, fmt.Errorf("invalid namespace exclusion %q: %w", namespace, err)
+		}
+		e.namespaces = append(e.namespaces, namespace)
+	}
+	for i, resource := range config.Resources {
+		var rule resourceExclusion
+		var err error
+		if rule.kind, err = compileExclusion(resource.Kind); err != nil {
+			return nil, fmt.Errorf("invalid kind of resource exclusion %d: %w", i, err)
+		}
+		if rule.name, err = compileExclusion(resource.Name); err != nil {
+			return nil, fmt.Errorf("invalid name of resource exclusion %d: %w", i, err)
+		}
+		if _, err := path.Match(resource.Namespace, ""); err != nil {
+			return nil, fmt.Errorf("invalid namespace of resource exclusion %d: %w", i, err)
+		}
+		rule.namespace = resource.Namespace
+		if len(resource.Analyzers) > 0 {
+			rule.analyzers = map[string]bool{}
+			for _, analyzer := range resource.Analyzers {
+				rule.analyzers[analyzer] = true
+			}
+		}
+		e.resources = append(e.resources, rule)
+	}
+	return e, nil
+}
+
+func compileExclusion(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+// withClient returns the same exclusions reading annotations with client.
+func (e *exclusions) withClient(client *kubernetes.Client) *exclusions {
+	if e == nil {
+		return nil
+	}
+	return &exclusions{namespaces: e.namespaces, resources: e.resources, client: client}
+}
+
+// filter returns the results that are not excluded. The ignore annotations
+// of the objects of the results, and of their parents, are read with up to
+// concurrency requests at a time.
+func (e *exclusions) filter(ctx context.Context, results []common.Result, concurrency int) []common.Result {
+	if e == nil {
+		return results
+	}
+	configured := results[:0]
+	for _, result := range results {
+		if !e.excluded(result) {
+			configured = append(configured, result)
+		}
+	}
+
+	// Annotations are read on every run, they may change in watch mode.
+	annotations := e.ignoreAnnotations(ctx, configured, concurrency)
+	filtered := configured[:0]
+	for _, result := range configured {
+		ignored := false
+		namespace, objects := resultObjects(result)
+		for _, object := range objects {
+			if ignoresAnalyzer(annotations[objectKey(object[0], namespace, object[1])], result.Analyzer) {
+				ignored = true
+				break
+			}
+		}
+		if !ignored {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// resultObjects returns the namespace of the result, and the kind and name
+// of its object and of its parent.
+func resultObjects(result common.Result) (string, [][2]string) {
+	namespace, name, found := strings.Cut(result.Name, "/")
+	if !found {
+		namespace, name = "", result.Name
+	}
+	objects := [][2]string{{result.Kind, name}}
+	if parentKind, parentName, found := strings.Cut(result.ParentObject, "/"); found {
+		objects = append(objects, [2]string{parentKind, parentName})
+	}
+	return namespace, objects
+}
+
+// excluded returns whether the result is excluded by the configuration.
+func (e *exclusions) excluded(result common.Result) bool {
+	namespace, objects := resultObjects(result)
+	if namespace != "" {
+		for _, pattern := range e.namespaces {
+			if ok, _ := path.Match(pattern, namespace); ok {
+				return true
+			}
+		}
+	}
+
+	// The result is matched by its own kind and name, and by its parent's.
+	for _, rule := range e.resources {
+		if rule.analyzers != nil && !rule.analyzers[result.Analyzer] {
+			continue
+		}
+		if rule.namespace != "" {
+			if ok, _ := path.Match(rule.namespace, namespace); !ok {
+				continue
+			}
+		}
+		for _, object := range objects {
+			if (rule.kind == nil || rule.kind.MatchString(object[0])) &&
+				(rule.name == nil || rule.name.MatchString(object[1])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ignoresAnalyzer(annotation string, analyzer string) bool {
+	for _, value := range strings.Split(annotation, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || (value != "" && value == analyzer) {
+			return true
+		}
+	}
+	return false
+}
+
+func objectKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// ignoreAnnotations returns the ignore annotations of the objects of the
+// results by objectKey. Objects that cannot be read have none.
+func (e *exclusions) ignoreAnnotations(ctx context.Context, results []common.Result, concurrency int) map[string]string {
+	annotations := map[string]string{}
+	if e.client == nil || e.client.CtrlClient == nil {
+		return annotations
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, max(concurrency, 1))
+	requested := map[string]bool{}
+	for _, result := range results {
+		namespace, objects := resultObjects(result)
+		for _, object := range objects {
+			gvk, ok := objectKinds[object[0]]
+			key := objectKey(object[0], namespace, object[1])
+			if !ok || requested[key] {
+				continue
+			}
+			requested[key] = true
+
+			wg.Add(1)
+			semaphore <- struct{}{}
+			go func(gvk schema.GroupVersionKind, name string) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				metadata := &metav1.PartialObjectMetadata{}
+				metadata.SetGroupVersionKind(gvk)
+				if err := e.client.CtrlClient.Get(ctx, ctrl.ObjectKey{Namespace: namespace, Name: name}, metadata); err != nil {
+					return
+				}
+				mutex.Lock()
+				defer mutex.Unlock()
+				annotations[key] = metadata.GetAnnotations()[IgnoreAnnotation]
+			}(gvk, object[1])
+		}
+	}
+	wg.Wait()
+	return annotations
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestExclusions(t *testing.T) {
+	var gets atomic.Int32
+	ctrlClient := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, client ctrl.WithWatch, key ctrl.ObjectKey, obj ctrl.Object, opts ...ctrl.GetOption) error {
+			gets.Add(1)
+			return client.Get(ctx, key, obj, opts...)
+		},
+	}).WithScheme(scheme.Scheme).WithObjects(
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "node-exporter",
+				Namespace:   "monitoring",
+				Annotations: map[string]string{IgnoreAnnotation: "PrivilegedContainer, HostPath"},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "debug",
+				Namespace:   "default",
+				Annotations: map[string]string{IgnoreAnnotation: "*"},
+			},
+		},
+	).Build()
+
+	e, err := newExclusions(ExclusionConfig{
+		Namespaces: []string{"kube-*"},
+		Resources: []ResourceExclusion{
+			{Kind: "Service", Name: "legacy-.*"},
+			{Name: "fluent-bit", Namespace: "logging", Analyzers: []string{"RootUser"}},
+		},
+	}, &kubernetes.Client{CtrlClient: ctrlClient})
+	require.NoError(t, err)
+
+	results := []common.Result{
+		{Kind: "Pod", Name: "kube-system/coredns-abcde", Analyzer: "Pod"},
+		{Kind: "Service", Name: "default/legacy-api", Analyzer: "Service"},
+		{Kind: "Service", Name: "default/legacy", Analyzer: "Service"},
+		{Kind: "Pod", Name: "logging/fluent-bit-abcde", ParentObject: "DaemonSet/fluent-bit", Analyzer: "RootUser"},
+		{Kind: "Pod", Name: "logging/fluent-bit-abcde", ParentObject: "DaemonSet/fluent-bit", Analyzer: "Pod"},
+		{Kind: "Pod", Name: "monitoring/node-exporter-abcde", ParentObject: "DaemonSet/node-exporter", Analyzer: "PrivilegedContainer"},
+		{Kind: "Pod", Name: "monitoring/node-exporter-abcde", ParentObject: "DaemonSet/node-exporter", Analyzer: "RootUser"},
+		{Kind: "Pod", Name: "default/debug", Analyzer: "Pod"},
+		{Kind: "Node", Name: "node-1", Analyzer: "Node"},
+	}
+
+	remaining := []string{}
+	for _, result := range e.filter(context.Background(), results, 2) {
+		remaining = append(remaining, result.Analyzer+" "+result.Name)
+	}
+	require.Equal(t, []string{
+		"Service default/legacy",
+		"Pod logging/fluent-bit-abcde",
+		"RootUser monitoring/node-exporter-abcde",
+		"Node node-1",
+	}, remaining)
+	// The objects of the results excluded by the configuration are not read,
+	// and the others are read once.
+	require.Equal(t, int32(7), gets.Load())
+}
+
+func TestNewExclusions_Invalid(t *testing.T) {
+	_, err := newExclusions(ExclusionConfig{Namespaces: []string{"kube-["}}, nil)
+	require.ErrorContains(t, err, "invalid namespace exclusion")
+
+	_, err = newExclusions(ExclusionConfig{Resources: []ResourceExclusion{{Kind: "Pod("}}}, nil)
+	require.ErrorContains(t, err, "invalid kind of resource exclusion 0")
+}
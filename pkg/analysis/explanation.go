@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+// freeTextExplanation matches the "Error: ... Solution: ..." format asked
+// for by the built-in prompts.
+var freeTextExplanation = regexp.MustCompile(`(?is)^\s*error:\s*(.*?)\s*solution:\s*(.*?)\s*$`)
+
+// stepPrefix matches the numbering or bullet of a step.
+var stepPrefix = regexp.MustCompile(`^(?:\d+[.)]|[-*])\s*`)
+
+// withStructuredPrompt requests a structured explanation, if enabled. It
+// returns the prompt and its input in the cache.
+func (a *Analysis) withStructuredPrompt(prompt, cacheInput string) (string, string) {
+	if !a.structured {
+		return prompt, cacheInput
+	}
+	// Structured explanations are cached apart from the free text ones.
+	return prompt + "\n" + ai.StructuredPrompt, "structured:" + cacheInput
+}
+
+// parseExplanation parses an explanation requested with ai.StructuredPrompt.
+// Backends ignoring the requested format get their "Error: ... Solution: ..."
+// answer, or else the whole answer, as summary. It returns the explanation and
+// the details of the result, rendered from the explanation if it was
+// structured and the answer as is otherwise.
+func parseExplanation(text string) (*common.Explanation, string) {
+	if explanation := parseStructuredExplanation(text); explanation != nil {
+		return explanation, renderExplanation(explanation)
+	}
+
+	explanation := &common.Explanation{Summary: strings.TrimSpace(text)}
+	if match := freeTextExplanation.FindStringSubmatch(text); match != nil {
+		explanation.Summary = match[1]
+		for _, line := range strings.Split(match[2], "\n") {
+			step := stepPrefix.ReplaceAllString(strings.TrimSpace(line), "")
+			if step == "" {
+				continue
+			}
+			explanation.Steps = append(explanation.Steps, step)
+			if command := strings.Trim(step, "`"); strings.HasPrefix(command, "kubectl ") {
+				explanation.Commands = append(explanation.Commands, command)
+			}
+		}
+	}
+	return explanation, text
+}
+
+// parseStructuredExplanation returns nil unless text holds a JSON explanation
+// with a summary, possibly wrapped in a markdown code block.
+func parseStructuredExplanation(text string) *common.Explanation {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil
+	}
+	var explanation common.Explanation
+	if err := json.Unmarshal([]byte(text[start:end+1]), &explanation); err != nil {
+		return nil
+	}
+	explanation.Summary = strings.TrimSpace(explanation.Summary)
+	if explanation.Summary == "" {
+		return nil
+	}
+	explanation.RootCause = strings.TrimSpace(explanation.RootCause)
+	explanation.Steps = nonEmpty(explanation.Steps)
+	explanation.Commands = nonEmpty(explanation.Commands)
+	switch confidence := strings.ToLower(strings.TrimSpace(explanation.Confidence)); confidence {
+	case "low", "medium", "high":
+		explanation.Confidence = confidence
+	default:
+		explanation.Confidence = ""
+	}
+	return &explanation
+}
+
+func nonEmpty(values []string) []string {
+	var kept []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
+
+// renderExplanation renders a structured explanation in the format of the
+// free text ones.
+func renderExplanation(explanation *common.Explanation) string {
+	var details strings.Builder
+	fmt.Fprintf(&details, "Error: %s\n", explanation.Summary)
+	if explanation.RootCause != "" {
+		fmt.Fprintf(&details, "Root cause: %s\n", explanation.RootCause)
+	}
+	if len(explanation.Steps) > 0 {
+		details.WriteString("Solution:\n")
+		for i, step := range explanation.Steps {
+			fmt.Fprintf(&details, "%d. %s\n", i+1, step)
+		}
+	}
+	if len(explanation.Commands) > 0 {
+		details.WriteString("Commands:\n")
+		for _, command := range explanation.Commands {
+			fmt.Fprintf(&details, "  %s\n", command)
+		}
+	}
+	if explanation.Confidence != "" {
+		fmt.Fprintf(&details, "Confidence: %s\n", explanation.Confidence)
+	}
+	return details.String()
+}
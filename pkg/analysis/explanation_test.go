@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExplanation(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		explanation *common.Explanation
+		details     string
+	}{
+		{
+			name: "structured",
+			text: "```json\n{\"summary\": \"The image can't be pulled.\", \"rootCause\": \"The tag does not exist.\", " +
+				"\"steps\": [\"Fix the tag.\", \" \"], \"commands\": [\"kubectl describe pod web\"], \"confidence\": \"High\"}\n```",
+			explanation: &common.Explanation{
+				Summary:    "The image can't be pulled.",
+				RootCause:  "The tag does not exist.",
+				Steps:      []string{"Fix the tag."},
+				Commands:   []string{"kubectl describe pod web"},
+				Confidence: "high",
+			},
+			details: "Error: The image can't be pulled.\nRoot cause: The tag does not exist.\nSolution:\n1. Fix the tag.\n" +
+				"Commands:\n  kubectl describe pod web\nConfidence: high\n",
+		},
+		{
+			name:        "invalid confidence",
+			text:        `{"summary": "The pod is pending.", "confidence": "certain"}`,
+			explanation: &common.Explanation{Summary: "The pod is pending."},
+			details:     "Error: The pod is pending.\n",
+		},
+		{
+			name: "free text",
+			text: "Error: The image can't be pulled.\nSolution: 1. Fix the tag.\n2. `kubectl rollout restart deployment web`",
+			explanation: &common.Explanation{
+				Summary:  "The image can't be pulled.",
+				Steps:    []string{"Fix the tag.", "`kubectl rollout restart deployment web`"},
+				Commands: []string{"kubectl rollout restart deployment web"},
+			},
+			details: "Error: The image can't be pulled.\nSolution: 1. Fix the tag.\n2. `kubectl rollout restart deployment web`",
+		},
+		{
+			name:        "unknown format",
+			text:        " The image can't be pulled {yet}. ",
+			explanation: &common.Explanation{Summary: "The image can't be pulled {yet}."},
+			details:     " The image can't be pulled {yet}. ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			explanation, details := parseExplanation(tt.text)
+			require.Equal(t, tt.explanation, explanation)
+			require.Equal(t, tt.details, details)
+		})
+	}
+}
+
+type structuredAIClient struct {
+	ai.NoOpAIClient
+}
+
+func (c *structuredAIClient) GetCompletion(_ context.Context, prompt string) (string, error) {
+	if !strings.HasSuffix(prompt, ai.StructuredPrompt) {
+		return "Error: unstructured", nil
+	}
+	return `{"summary": "structured", "confidence": "low"}`, nil
+}
+
+func TestGetAIResults_Structured(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := Analysis{
+		Context:  context.Background(),
+		AIClient: &structuredAIClient{},
+		Cache:    disabledCache,
+		Results:  []common.Result{{Kind: "Pod", Name: "default/web", Error: []common.Failure{{Text: "failure"}}}},
+	}
+
+	require.NoError(t, a.GetAIResults("json", false))
+	require.Equal(t, &common.Explanation{Summary: "structured", Confidence: "low"}, a.Results[0].Explanation)
+	require.Equal(t, "Error: structured\nConfidence: low\n", a.Results[0].Details)
+
+	a.Results[0].Explanation = nil
+	require.NoError(t, a.GetAIResults("text", false))
+	require.Nil(t, a.Results[0].Explanation)
+	require.Equal(t, "Error: unstructured", a.Results[0].Details)
+}
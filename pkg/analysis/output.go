@@ -6,11 +6,15 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 )
 
 var outputFormats = map[string]func(*Analysis) ([]byte, error){
-	"json": (*Analysis).jsonOutput,
-	"text": (*Analysis).textOutput,
+	"json":     (*Analysis).jsonOutput,
+	"junit":    (*Analysis).junitOutput,
+	"markdown": (*Analysis).markdownOutput,
+	"sarif":    (*Analysis).sarifOutput,
+	"text":     (*Analysis).textOutput,
 }
 
 func getOutputFormats() []string {
@@ -42,11 +46,13 @@ func (a *Analysis) jsonOutput() ([]byte, error) {
 	}
 
 	result := JsonOutput{
-		Provider: a.AnalysisAIProvider,
-		Problems: problems,
-		Results:  a.Results,
-		Errors:   a.Errors,
-		Status:   status,
+		Provider:   a.AnalysisAIProvider,
+		Problems:   problems,
+		Suppressed: a.Suppressed,
+		Results:    a.Results,
+		Errors:     a.Errors,
+		Status:     status,
+		Usage:      a.AIUsage(),
 	}
 	output, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
@@ -63,6 +69,7 @@ func (a *Analysis) PrintStats() []byte {
 	for _, stat := range a.Stats {
 		output.WriteString(fmt.Sprintf("- Analyzer %s took %s \n", color.YellowString(stat.Analyzer), stat.DurationTime))
 	}
+	a.writeUsage(&output)
 
 	return []byte(output.String())
 }
@@ -70,6 +77,22 @@ func (a *Analysis) PrintStats() []byte {
 func (a *Analysis) textOutput() ([]byte, error) {
 	var output strings.Builder
 
+	if !a.writeTextHeader(&output) {
+		return []byte(output.String()), nil
+	}
+	for n, result := range a.Results {
+		writeTextResult(&output, n, result)
+		output.WriteString(color.GreenString(result.Details + "\n"))
+		output.WriteString(a.fallbackNote(result))
+		output.WriteString(remediationText(result))
+	}
+	a.writeUsage(&output)
+	return []byte(output.String()), nil
+}
+
+// writeTextHeader writes what the text output shows before the results, and
+// reports whether there are results to show.
+func (a *Analysis) writeTextHeader(output *strings.Builder) bool {
 	// Print the AI provider used for this analysis (if explain was enabled).
 	if a.Explain {
 		output.WriteString(fmt.Sprintf("AI Provider: %s\n", color.YellowString(a.AnalysisAIProvider)))
@@ -84,23 +107,71 @@ func (a *Analysis) textOutput() ([]byte, error) {
 			output.WriteString(fmt.Sprintf("- %s\n", color.YellowString(aerror)))
 		}
 	}
+	if a.Suppressed > 0 {
+		output.WriteString(fmt.Sprintf("\n%s %d\n", color.YellowString("Suppressed problems:"), a.Suppressed))
+	}
 	output.WriteString("\n")
 	if len(a.Results) == 0 {
 		output.WriteString(color.GreenString("No problems detected\n"))
-		return []byte(output.String()), nil
+		return false
 	}
-	for n, result := range a.Results {
-		output.WriteString(fmt.Sprintf("%s: %s %s(%s)\n", color.CyanString("%d", n),
-			color.HiYellowString(result.Kind),
-			color.YellowString(result.Name),
-			color.CyanString(result.ParentObject)))
-		for _, err := range result.Error {
-			output.WriteString(fmt.Sprintf("- %s %s\n", color.RedString("Error:"), color.RedString(err.Text)))
-			if err.KubernetesDoc != "" {
-				output.WriteString(fmt.Sprintf("  %s %s\n", color.RedString("Kubernetes Doc:"), color.RedString(err.KubernetesDoc)))
+	return true
+}
+
+// writeTextResult writes the n-th result of the text output, without its
+// explanation.
+func writeTextResult(output *strings.Builder, n int, result common.Result) {
+	cluster := ""
+	if result.Cluster != "" {
+		cluster = color.MagentaString("[%s] ", result.Cluster)
+	}
+	output.WriteString(fmt.Sprintf("%s: %s%s %s(%s)\n", color.CyanString("%d", n), cluster,
+		color.HiYellowString(result.Kind),
+		color.YellowString(result.Name),
+		color.CyanString(result.ParentObject)))
+	for _, err := range result.Error {
+		output.WriteString(fmt.Sprintf("- %s %s %s\n", color.RedString("Error:"), severityLabel(err.GetSeverity()), color.RedString(err.Text)))
+		if err.KubernetesDoc != "" {
+			output.WriteString(fmt.Sprintf("  %s %s\n", color.RedString("Kubernetes Doc:"), color.RedString(err.KubernetesDoc)))
+		}
+	}
+}
+
+// fallbackNote tells which fallback provider explained the result, if the
+// selected provider did not.
+func (a *Analysis) fallbackNote(result common.Result) string {
+	if result.Provider == "" || result.Provider == a.AnalysisAIProvider {
+		return ""
+	}
+	return color.YellowString("(explained by %s)\n", result.Provider)
+}
+
+// severityLabel renders a severity for the text output, e.g. "[high]".
+func severityLabel(severity common.Severity) string {
+	label := fmt.Sprintf("[%s]", severity)
+	switch severity {
+	case common.SeverityCritical:
+		return color.New(color.FgHiRed, color.Bold).Sprint(label)
+	case common.SeverityHigh:
+		return color.HiRedString(label)
+	case common.SeverityMedium:
+		return color.YellowString(label)
+	case common.SeverityLow:
+		return color.CyanString(label)
+	default:
+		return color.WhiteString(label)
+	}
+}
+
+// CountAtLeast returns the number of failures at least as severe as threshold.
+func (a *Analysis) CountAtLeast(threshold common.Severity) int {
+	var count int
+	for _, result := range a.Results {
+		for _, failure := range result.Error {
+			if failure.GetSeverity().AtLeast(threshold) {
+				count++
 			}
 		}
-		output.WriteString(color.GreenString(result.Details + "\n"))
 	}
-	return []byte(output.String()), nil
+	return count
 }
@@ -16,6 +16,7 @@ package analysis
 import (
 	"testing"
 
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -62,3 +63,43 @@ func TestPrintOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestCountAtLeast(t *testing.T) {
+	a := &Analysis{
+		Results: []common.Result{
+			{
+				Kind: "Pod",
+				Name: "default/example",
+				Error: []common.Failure{
+					{Text: "crash", Severity: common.SeverityCritical},
+					{Text: "seccomp", Severity: common.SeverityMedium},
+				},
+			},
+			{
+				Kind:  "Service",
+				Name:  "default/example",
+				Error: []common.Failure{{Text: "custom analyzer failure without severity"}},
+			},
+		},
+	}
+
+	require.Equal(t, 1, a.CountAtLeast(common.SeverityCritical))
+	require.Equal(t, 1, a.CountAtLeast(common.SeverityHigh))
+	require.Equal(t, 3, a.CountAtLeast(common.SeverityMedium))
+	require.Equal(t, 3, a.CountAtLeast(common.SeverityInfo))
+
+	output, err := a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "[critical]")
+}
+
+func TestParseSeverity(t *testing.T) {
+	severity, err := common.ParseSeverity("HIGH")
+	require.NoError(t, err)
+	require.Equal(t, common.SeverityHigh, severity)
+	require.True(t, severity.AtLeast(common.SeverityMedium))
+	require.False(t, severity.AtLeast(common.SeverityCritical))
+
+	_, err = common.ParseSeverity("urgent")
+	require.ErrorContains(t, err, "must be one of critical, high, medium, low, info")
+}
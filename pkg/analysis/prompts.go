@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxPromptEvents is the number of most recent events given to prompts.
+const maxPromptEvents = 10
+
+// renderPrompt executes a user-defined prompt for the failures of a result.
+// When anonymizing, the sensitive values of the result are masked in the
+// whole prompt, not only in the failures.
+func (a *Analysis) renderPrompt(tmpl *ai.PromptTemplate, result common.Result, failures []string, anonymize bool) (string, error) {
+	namespace, name, found := strings.Cut(result.Name, "/")
+	if !found {
+		namespace, name = "", result.Name
+	}
+	var events []string
+	if tmpl.UsesEvents() {
+		events = a.objectEvents(result.Kind, namespace, name)
+	}
+	prompt, err := tmpl.Render(map[string]interface{}{
+		"kind":      result.Kind,
+		"name":      name,
+		"namespace": namespace,
+		"parent":    result.ParentObject,
+		"language":  a.Language,
+		"failures":  failures,
+		"events":    events,
+	})
+	if err != nil {
+		return "", err
+	}
+	if anonymize {
+		for _, failure := range result.Error {
+			for _, s := range failure.Sensitive {
+				prompt = util.ReplaceIfMatch(prompt, s.Unmasked, s.Masked)
+			}
+		}
+	}
+	return prompt, nil
+}
+
+// objectEvents returns the most recent events of an object, as
+// "Type Reason: Message". Events only add context to the prompt, so they are
+// left out when they can't be listed.
+func (a *Analysis) objectEvents(kind, namespace, name string) []string {
+	if a.Client == nil || a.Client.Client == nil {
+		return nil
+	}
+	ctx := a.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	list, err := a.Client.Client.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind, name),
+	})
+	if err != nil {
+		return nil
+	}
+	items := list.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].LastTimestamp.Before(&items[j].LastTimestamp)
+	})
+	if len(items) > maxPromptEvents {
+		items = items[len(items)-maxPromptEvents:]
+	}
+	var events []string
+	for _, event := range items {
+		events = append(events, fmt.Sprintf("%s %s: %s", event.Type, event.Reason, event.Message))
+	}
+	return events
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestExplainResult_Prompts(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	prompts, err := ai.NewPrompts([]ai.PromptConfig{{
+		Kind:     "Pod",
+		Template: `{{.kind}} {{.namespace}}/{{.name}} of {{.parent}} in {{.language}}: {{join .failures "; "}} events: {{join .events "; "}}`,
+	}})
+	require.NoError(t, err)
+	a := Analysis{
+		Context: context.Background(),
+		Client: &kubernetes.Client{Client: fake.NewSimpleClientset(&v1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.1", Namespace: "default"},
+			InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "web"},
+			Type:           "Warning",
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+		})},
+		Language: "english",
+		AIClient: &ai.NoOpAIClient{},
+		Cache:    disabledCache,
+		prompts:  prompts,
+	}
+	result := common.Result{
+		Kind:         "Pod",
+		Name:         "default/web",
+		ParentObject: "Deployment/web",
+		Error: []common.Failure{{
+			Text:      "the container of web crashed",
+			Sensitive: []common.Sensitive{{Unmasked: "web", Masked: "xyz"}},
+		}},
+	}
+
+	details, _, err := a.explainResult(result, false, nil)
+	require.NoError(t, err)
+	require.Equal(t, "I am a noop response to the prompt Pod default/web of Deployment/web in english: "+
+		"the container of web crashed events: Warning BackOff: Back-off restarting failed container", details)
+
+	// Anonymizing masks the whole prompt, and unmasks the explanation.
+	a.AIClient = &failingAIClient{fail: "web"}
+	details, _, err = a.explainResult(result, true, nil)
+	require.NoError(t, err)
+	require.Contains(t, details, "Pod default/web of Deployment/web")
+
+	// Other kinds keep the built-in prompt.
+	a.AIClient = &ai.NoOpAIClient{}
+	result.Kind = "Service"
+	details, _, err = a.explainResult(result, false, nil)
+	require.NoError(t, err)
+	require.Contains(t, details, "Simplify the following Kubernetes error message")
+}
@@ -0,0 +1,365 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/pmezard/go-difflib/difflib"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// remediationPatchTypes maps the patch types proposed by the AI backend.
+var remediationPatchTypes = map[string]types.PatchType{
+	"strategic": types.StrategicMergePatchType,
+	"merge":     types.MergePatchType,
+	"json":      types.JSONPatchType,
+}
+
+// Remediate asks the AI backend for a patch fixing the object of every
+// explained result, and validates it with a server-side dry-run. Results of
+// kinds that can't be fetched are skipped, and the ones that can't be
+// remediated are reported as warnings.
+func (a *Analysis) Remediate() {
+	errs := make([]error, len(a.Results))
+	semaphore := make(chan struct{}, max(a.MaxConcurrency, 1))
+	var wg sync.WaitGroup
+	for index := range a.Results {
+		if a.Results[index].Details == "" || a.Results[index].Details == NotExplainedBudgetExhausted {
+			continue
+		}
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(index int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			a.Results[index].Remediation, errs[index] = a.remediate(a.Results[index])
+		}(index)
+	}
+	wg.Wait()
+
+	for index, err := range errs {
+		if err != nil {
+			result := a.Results[index]
+			a.Errors = append(a.Errors, fmt.Sprintf("failed to remediate %s %s: %v", result.Kind, result.Name, err))
+		}
+	}
+}
+
+// remediate proposes a patch for the object of a result. A proposal that
+// fails validation is returned with its error.
+func (a *Analysis) remediate(result common.Result) (*common.Remediation, error) {
+	ctx := a.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	obj, client, err := a.remediationObject(ctx, result)
+	if obj == nil || err != nil {
+		return nil, err
+	}
+	original, err := objectYAML(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []string
+	for _, failure := range result.Error {
+		failures = append(failures, failure.Text)
+	}
+	inputKey := strings.Join(failures, " ")
+	prompt := fmt.Sprintf(ai.RemediationPrompt, obj.GetKind(), original, inputKey, result.Details, a.Language)
+	// The prompt holds the object, which identifies the proposal in the cache.
+	text, _, err := a.getAIResult(inputKey, prompt, prompt, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	object := obj.GetKind() + "/" + obj.GetName()
+	remediation, patchType, err := parseRemediation(text)
+	if err != nil {
+		return &common.Remediation{Object: object, Type: "none", Error: err.Error()}, nil
+	}
+	remediation.Object = object
+	if remediation.Type == "none" {
+		return remediation, nil
+	}
+	remediation.ResourceVersion = obj.GetResourceVersion()
+	patched := obj.DeepCopy()
+	if err := client.CtrlClient.Patch(ctx, patched, ctrl.RawPatch(patchType, []byte(remediation.Patch)), ctrl.DryRunAll); err != nil {
+		remediation.Error = fmt.Sprintf("dry-run failed: %v", err)
+		return remediation, nil
+	}
+	modified, err := objectYAML(patched)
+	if err != nil {
+		return nil, err
+	}
+	name := object
+	remediation.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(original),
+		B:        difflib.SplitLines(modified),
+		FromFile: name,
+		ToFile:   name + " (patched)",
+		Context:  3,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if remediation.Diff == "" {
+		remediation.Error = "the patch does not change the object"
+	}
+	return remediation, nil
+}
+
+// ApplyRemediation applies the validated patch proposed for a result. The
+// patch is applied only if the object did not change since the dry-run.
+func (a *Analysis) ApplyRemediation(index int) error {
+	result := a.Results[index]
+	remediation := result.Remediation
+	if remediation == nil || remediation.Diff == "" || remediation.Error != "" {
+		return errors.New("no valid remediation to apply")
+	}
+	ctx := a.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	kind, name, _ := strings.Cut(remediation.Object, "/")
+	obj, client := a.clusterObject(result, kind, name)
+	if obj == nil {
+		return fmt.Errorf("unsupported kind %s", kind)
+	}
+	data, err := conditionalPatch(remediation)
+	if err != nil {
+		return err
+	}
+	patch := ctrl.RawPatch(remediationPatchTypes[remediation.Type], data)
+	if err := client.CtrlClient.Patch(ctx, obj, patch); err != nil {
+		// A failed test operation of a JSON patch is not reported as a
+		// conflict, the version of the object tells.
+		current := obj.DeepCopy()
+		changed := client.CtrlClient.Get(ctx, ctrl.ObjectKeyFromObject(obj), current) == nil &&
+			current.GetResourceVersion() != remediation.ResourceVersion
+		if apierrors.IsConflict(err) || changed {
+			return fmt.Errorf("%s %s changed since the dry-run, remediate it again", kind, ctrl.ObjectKeyFromObject(obj))
+		}
+		return err
+	}
+	remediation.Applied = true
+	return nil
+}
+
+// conditionalPatch returns the patch of a remediation, made conditional on
+// the resource version validated by the dry-run.
+func conditionalPatch(remediation *common.Remediation) ([]byte, error) {
+	if remediation.ResourceVersion == "" {
+		return nil, errors.New("no resource version to apply the patch to")
+	}
+	if remediation.Type == "json" {
+		var operations []json.RawMessage
+		if err := json.Unmarshal([]byte(remediation.Patch), &operations); err != nil {
+			return nil, fmt.Errorf("invalid patch: %w", err)
+		}
+		test, err := json.Marshal(map[string]string{
+			"op":    "test",
+			"path":  "/metadata/resourceVersion",
+			"value": remediation.ResourceVersion,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(append([]json.RawMessage{test}, operations...))
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal([]byte(remediation.Patch), &patch); err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	if patch == nil {
+		patch = map[string]interface{}{}
+	}
+	if err := unstructured.SetNestedField(patch, remediation.ResourceVersion, "metadata", "resourceVersion"); err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	return json.Marshal(patch)
+}
+
+// workloadKinds are the kinds whose pod template is the spec of their pods.
+var workloadKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+	"ReplicaSet":  true,
+	"Job":         true,
+	"CronJob":     true,
+}
+
+// remediationObject fetches the object to patch to fix a result, and returns
+// it with the client of its cluster. The pods of a workload are recreated
+// from its pod template, so the topmost workload owning a Pod is patched
+// rather than the Pod. The object is nil if it can't be fetched.
+func (a *Analysis) remediationObject(ctx context.Context, result common.Result) (*unstructured.Unstructured, *kubernetes.Client, error) {
+	kind, name := result.Kind, ""
+	if parentKind, parentName, ok := strings.Cut(result.ParentObject, "/"); ok && workloadKinds[parentKind] {
+		kind, name = parentKind, parentName
+	}
+	obj, client := a.clusterObject(result, kind, name)
+	if obj == nil {
+		return nil, nil, nil
+	}
+	if err := client.CtrlClient.Get(ctx, ctrl.ObjectKeyFromObject(obj), obj); err != nil {
+		return nil, nil, err
+	}
+	if kind != "Pod" && !workloadKinds[kind] {
+		return obj, client, nil
+	}
+	for {
+		owner := metav1.GetControllerOf(obj)
+		if owner == nil || !workloadKinds[owner.Kind] {
+			return obj, client, nil
+		}
+		parent := &unstructured.Unstructured{}
+		parent.SetGroupVersionKind(objectKinds[owner.Kind])
+		parent.SetNamespace(obj.GetNamespace())
+		parent.SetName(owner.Name)
+		if err := client.CtrlClient.Get(ctx, ctrl.ObjectKeyFromObject(parent), parent); err != nil {
+			return nil, nil, err
+		}
+		obj = parent
+	}
+}
+
+// clusterObject returns an object of kind in the namespace of a result, to be
+// fetched, and the client of its cluster. The name defaults to the one of the
+// result. The object is nil if it can't be fetched.
+func (a *Analysis) clusterObject(result common.Result, kind, name string) (*unstructured.Unstructured, *kubernetes.Client) {
+	gvk, ok := objectKinds[kind]
+	if !ok {
+		return nil, nil
+	}
+	client := a.Client
+	for _, c := range a.clusters {
+		if c.name == result.Cluster {
+			client = c.client
+		}
+	}
+	if client == nil || client.CtrlClient == nil {
+		return nil, nil
+	}
+	namespace, resultName, found := strings.Cut(result.Name, "/")
+	if !found {
+		namespace, resultName = "", result.Name
+	}
+	if name == "" {
+		name = resultName
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj, client
+}
+
+// objectYAML returns the YAML of an object without its status and the
+// metadata that changes on every write.
+func objectYAML(obj *unstructured.Unstructured) (string, error) {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "status")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "generation")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration")
+	data, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// parseRemediation parses a proposal requested with ai.RemediationPrompt,
+// possibly wrapped in a markdown code block.
+func parseRemediation(text string) (*common.Remediation, types.PatchType, error) {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return nil, "", errors.New("no patch proposed")
+	}
+	var response struct {
+		Type        string          `json:"type"`
+		Patch       json.RawMessage `json:"patch"`
+		Description string          `json:"description"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &response); err != nil {
+		return nil, "", fmt.Errorf("invalid patch proposal: %w", err)
+	}
+	remediation := &common.Remediation{
+		Type:        strings.ToLower(strings.TrimSpace(response.Type)),
+		Description: strings.TrimSpace(response.Description),
+	}
+	if remediation.Type == "none" {
+		return remediation, "", nil
+	}
+	patchType, ok := remediationPatchTypes[remediation.Type]
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported patch type %q", response.Type)
+	}
+	var patch bytes.Buffer
+	if err := json.Compact(&patch, response.Patch); err != nil {
+		return nil, "", fmt.Errorf("invalid patch: %w", err)
+	}
+	remediation.Patch = patch.String()
+	return remediation, patchType, nil
+}
+
+// remediationText renders the remediation of a result for the text output.
+func remediationText(result common.Result) string {
+	remediation := result.Remediation
+	if remediation == nil {
+		return ""
+	}
+	var output strings.Builder
+	output.WriteString(color.YellowString("Remediation: "))
+	if remediation.Description != "" {
+		output.WriteString(remediation.Description)
+	}
+	output.WriteString("\n")
+	if remediation.Error != "" {
+		output.WriteString(color.RedString("Invalid patch: %s\n", remediation.Error))
+		return output.String()
+	}
+	for _, line := range difflib.SplitLines(remediation.Diff) {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			output.WriteString(line)
+		case strings.HasPrefix(line, "+"):
+			output.WriteString(color.GreenString(line))
+		case strings.HasPrefix(line, "-"):
+			output.WriteString(color.RedString(line))
+		default:
+			output.WriteString(line)
+		}
+	}
+	return output.String()
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+type remediationAIClient struct {
+	ai.NoOpAIClient
+	response string
+}
+
+func (c *remediationAIClient) GetCompletion(_ context.Context, _ string) (string, error) {
+	return c.response, nil
+}
+
+// dryRunInterceptor applies the dry-run patches to a copy of the object, as
+// the API server does, since the fake client ignores them.
+func dryRunInterceptor() interceptor.Funcs {
+	return interceptor.Funcs{
+		Patch: func(ctx context.Context, client ctrl.WithWatch, obj ctrl.Object, patch ctrl.Patch, opts ...ctrl.PatchOption) error {
+			options := &ctrl.PatchOptions{}
+			options.ApplyOptions(opts)
+			if len(options.DryRun) == 0 {
+				return client.Patch(ctx, obj, patch, opts...)
+			}
+			current := obj.DeepCopyObject().(ctrl.Object)
+			if err := client.Get(ctx, ctrl.ObjectKeyFromObject(obj), current); err != nil {
+				return err
+			}
+			return fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(current).Build().Patch(ctx, obj, patch)
+		},
+	}
+}
+
+func TestRemediate(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	dryRun := dryRunInterceptor()
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(dryRun).WithObjects(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: "nginx:lastest"}}},
+			},
+		},
+	}).Build()
+	newAnalysis := func(response string) *Analysis {
+		return &Analysis{
+			Context:  context.Background(),
+			Client:   &kubernetes.Client{CtrlClient: ctrlClient},
+			AIClient: &remediationAIClient{response: response},
+			Cache:    disabledCache,
+			Results: []common.Result{
+				{Kind: "Deployment", Name: "default/web", Details: "The image tag is wrong."},
+				{Kind: "PolicyReport", Name: "default/report", Details: "Not an object kind."},
+				{Kind: "Service", Name: "default/web"},
+			},
+		}
+	}
+	image := func() string {
+		var deployment appsv1.Deployment
+		require.NoError(t, ctrlClient.Get(context.Background(), ctrl.ObjectKey{Namespace: "default", Name: "web"}, &deployment))
+		return deployment.Spec.Template.Spec.Containers[0].Image
+	}
+
+	a := newAnalysis("```json\n" + `{"type": "json", "patch": [{"op": "replace", "path": "/spec/template/spec/containers/0/image", "value": "nginx:latest"}], "description": "Fix the image tag."}` + "\n```")
+	a.Remediate()
+	require.Empty(t, a.Errors)
+	remediation := a.Results[0].Remediation
+	require.NotNil(t, remediation)
+	require.Equal(t, "json", remediation.Type)
+	require.Equal(t, "Fix the image tag.", remediation.Description)
+	require.Empty(t, remediation.Error)
+	require.Contains(t, remediation.Diff, "-      - image: nginx:lastest\n+      - image: nginx:latest\n")
+	require.Nil(t, a.Results[1].Remediation)
+	require.Nil(t, a.Results[2].Remediation)
+	// The dry-run leaves the object unchanged.
+	require.Equal(t, "nginx:lastest", image())
+
+	require.NotEmpty(t, remediation.ResourceVersion)
+	require.NoError(t, a.ApplyRemediation(0))
+	require.True(t, remediation.Applied)
+	require.Equal(t, "nginx:latest", image())
+	require.Error(t, a.ApplyRemediation(1))
+
+	// Patches are not applied to an object changed since the dry-run.
+	setImage := func(image string) {
+		var deployment appsv1.Deployment
+		require.NoError(t, ctrlClient.Get(context.Background(), ctrl.ObjectKey{Namespace: "default", Name: "web"}, &deployment))
+		deployment.Spec.Template.Spec.Containers[0].Image = image
+		require.NoError(t, ctrlClient.Update(context.Background(), &deployment))
+	}
+	for _, response := range []string{
+		`{"type": "json", "patch": [{"op": "replace", "path": "/spec/template/spec/containers/0/image", "value": "nginx:1.27"}]}`,
+		`{"type": "merge", "patch": {"spec": {"template": {"spec": {"containers": [{"name": "web", "image": "nginx:1.27"}]}}}}}`,
+	} {
+		a = newAnalysis(response)
+		a.Remediate()
+		require.Empty(t, a.Results[0].Remediation.Error)
+		setImage("nginx:1.26")
+		require.EqualError(t, a.ApplyRemediation(0), "Deployment default/web changed since the dry-run, remediate it again")
+		require.False(t, a.Results[0].Remediation.Applied)
+		require.Equal(t, "nginx:1.26", image())
+	}
+	setImage("nginx:latest")
+
+	// Invalid proposals are kept with their error.
+	a = newAnalysis(`{"type": "json", "patch": [{"op": "replace", "path": "/spec/missing/0", "value": 1}]}`)
+	a.Remediate()
+	require.Contains(t, a.Results[0].Remediation.Error, "dry-run failed")
+	require.Error(t, a.ApplyRemediation(0))
+
+	a = newAnalysis(`{"type": "json", "patch": [{"op": "replace", "path": "/spec/template/spec/containers/0/image", "value": "nginx:latest"}]}`)
+	a.Remediate()
+	require.Equal(t, "the patch does not change the object", a.Results[0].Remediation.Error)
+
+	a = newAnalysis(`{"type": "none", "description": "The image must be pushed."}`)
+	a.Remediate()
+	require.Equal(t, &common.Remediation{Object: "Deployment/web", Type: "none", Description: "The image must be pushed."}, a.Results[0].Remediation)
+
+	a = newAnalysis("I can't help with that.")
+	a.Remediate()
+	require.Equal(t, &common.Remediation{Object: "Deployment/web", Type: "none", Error: "no patch proposed"}, a.Results[0].Remediation)
+
+	a = newAnalysis(`{"type": "apply", "patch": {}}`)
+	a.Remediate()
+	require.Equal(t, `unsupported patch type "apply"`, a.Results[0].Remediation.Error)
+}
+
+func TestRemediate_PodOfWorkload(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	controller := true
+	ownedBy := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: kind, Name: name, Controller: &controller}}
+	}
+	podSpec := v1.PodSpec{Containers: []v1.Container{{Name: "web", Image: "nginx:lastest"}}}
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithInterceptorFuncs(dryRunInterceptor()).WithObjects(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Template: v1.PodTemplateSpec{Spec: podSpec}},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-5c6b7a", Namespace: "default", OwnerReferences: ownedBy("Deployment", "web")},
+			Spec:       appsv1.ReplicaSetSpec{Template: v1.PodTemplateSpec{Spec: podSpec}},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-5c6b7a-abcde", Namespace: "default", OwnerReferences: ownedBy("ReplicaSet", "web-5c6b7a")},
+			Spec:       podSpec,
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "standalone", Namespace: "default"},
+			Spec:       podSpec,
+		},
+	).Build()
+	a := &Analysis{
+		Context: context.Background(),
+		Client:  &kubernetes.Client{CtrlClient: ctrlClient},
+		AIClient: &remediationAIClient{
+			response: `{"type": "merge", "patch": {"metadata": {"labels": {"fixed": "true"}}}}`,
+		},
+		Cache: disabledCache,
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/web-5c6b7a-abcde", ParentObject: "Deployment/web", Details: "The image tag is wrong."},
+			// Without a parent object, the owners of the Pod are followed.
+			{Kind: "Pod", Name: "default/web-5c6b7a-abcde", Details: "The image tag is wrong."},
+			{Kind: "Pod", Name: "default/standalone", Details: "The image tag is wrong."},
+		},
+	}
+
+	a.Remediate()
+	require.Empty(t, a.Errors)
+	require.Equal(t, "Deployment/web", a.Results[0].Remediation.Object)
+	require.Equal(t, "Deployment/web", a.Results[1].Remediation.Object)
+	require.Equal(t, "Pod/standalone", a.Results[2].Remediation.Object)
+	require.Contains(t, a.Results[0].Remediation.Diff, "--- Deployment/web\n")
+
+	require.NoError(t, a.ApplyRemediation(0))
+	var deployment appsv1.Deployment
+	require.NoError(t, ctrlClient.Get(context.Background(), ctrl.ObjectKey{Namespace: "default", Name: "web"}, &deployment))
+	require.Equal(t, "true", deployment.Labels["fixed"])
+	var pod v1.Pod
+	require.NoError(t, ctrlClient.Get(context.Background(), ctrl.ObjectKey{Namespace: "default", Name: "web-5c6b7a-abcde"}, &pod))
+	require.Empty(t, pod.Labels)
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+)
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+	SystemErr string          `xml:"system-err,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitOutput reports every analyzer as a testsuite with one failing
+// testcase per result. Warnings are reported as the system-err of a
+// separate k8sgpt testsuite.
+func (a *Analysis) junitOutput() ([]byte, error) {
+	suites := map[string]*junitTestSuite{}
+	for _, result := range a.Results {
+		name := ruleID(result)
+		suite, ok := suites[name]
+		if !ok {
+			suite = &junitTestSuite{Name: name}
+			suites[name] = suite
+		}
+
+		texts := make([]string, 0, len(result.Error))
+		lines := make([]string, 0, len(result.Error))
+		for _, failure := range result.Error {
+			texts = append(texts, failure.Text)
+			lines = append(lines, fmt.Sprintf("- [%s] %s", failure.GetSeverity(), failure.Text))
+		}
+		body := strings.Join(lines, "\n")
+		if result.ParentObject != "" {
+			body = fmt.Sprintf("Parent: %s\n%s", result.ParentObject, body)
+		}
+		if result.Details != "" {
+			body += "\n\n" + result.Details
+		}
+
+		suite.Tests++
+		suite.Failures++
+		testName := result.Name
+		if result.Cluster != "" {
+			testName = result.Cluster + "/" + testName
+		}
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      testName,
+			ClassName: result.Kind,
+			Failure: &junitFailure{
+				Message: strings.Join(texts, "; "),
+				Type:    string(result.HighestSeverity()),
+				Text:    body,
+			},
+		})
+	}
+
+	names := make([]string, 0, len(suites))
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := junitTestSuites{Name: "k8sgpt"}
+	for _, name := range names {
+		suite := suites[name]
+		sort.Slice(suite.TestCases, func(i, j int) bool {
+			return suite.TestCases[i].Name < suite.TestCases[j].Name
+		})
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.TestSuites = append(report.TestSuites, *suite)
+	}
+	if len(a.Errors) != 0 {
+		report.TestSuites = append(report.TestSuites, junitTestSuite{
+			Name:      "k8sgpt",
+			SystemErr: strings.Join(a.Errors, "\n"),
+		})
+	}
+
+	output, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling junit: %v", err)
+	}
+	return append([]byte(xml.Header), output...), nil
+}
+
+// markdownOutput renders the results as tables grouped by namespace and kind,
+// e.g. to be posted as a pull request comment.
+func (a *Analysis) markdownOutput() ([]byte, error) {
+	var output strings.Builder
+
+	output.WriteString("# K8sGPT analysis\n\n")
+	if a.Explain {
+		output.WriteString(fmt.Sprintf("AI Provider: `%s`\n\n", a.AnalysisAIProvider))
+	}
+
+	if len(a.Errors) != 0 {
+		output.WriteString("**Warnings**\n\n")
+		for _, aerror := range a.Errors {
+			output.WriteString(fmt.Sprintf("- %s\n", aerror))
+		}
+		output.WriteString("\n")
+	}
+
+	if a.Suppressed > 0 {
+		output.WriteString(fmt.Sprintf("%d problems suppressed\n\n", a.Suppressed))
+	}
+
+	if len(a.Results) == 0 {
+		output.WriteString("No problems detected\n")
+		return []byte(output.String()), nil
+	}
+
+	var problems int
+	// cluster -> namespace -> kind -> results
+	groups := map[string]map[string]map[string][]common.Result{}
+	for _, result := range a.Results {
+		problems += len(result.Error)
+		namespace := ""
+		if ns, _, found := strings.Cut(result.Name, "/"); found {
+			namespace = ns
+		}
+		if groups[result.Cluster] == nil {
+			groups[result.Cluster] = map[string]map[string][]common.Result{}
+		}
+		if groups[result.Cluster][namespace] == nil {
+			groups[result.Cluster][namespace] = map[string][]common.Result{}
+		}
+		groups[result.Cluster][namespace][result.Kind] = append(groups[result.Cluster][namespace][result.Kind], result)
+	}
+	output.WriteString(fmt.Sprintf("%d problems detected in %d resources\n", problems, len(a.Results)))
+
+	for _, cluster := range sortedKeys(groups) {
+		if cluster != "" {
+			output.WriteString(fmt.Sprintf("\n# Cluster `%s`\n", cluster))
+		}
+		writeMarkdownNamespaces(&output, groups[cluster])
+	}
+	return []byte(output.String()), nil
+}
+
+func writeMarkdownNamespaces(output *strings.Builder, namespaces map[string]map[string][]common.Result) {
+	for _, namespace := range sortedKeys(namespaces) {
+		if namespace == "" {
+			output.WriteString("\n## Cluster scoped\n")
+		} else {
+			output.WriteString(fmt.Sprintf("\n## Namespace `%s`\n", namespace))
+		}
+		kinds := namespaces[namespace]
+		for _, kind := range sortedKeys(kinds) {
+			results := kinds[kind]
+			sort.Slice(results, func(i, j int) bool {
+				return results[i].Name < results[j].Name
+			})
+
+			output.WriteString(fmt.Sprintf("\n### %s\n\n", kind))
+			output.WriteString("| Name | Parent | Errors | Details |\n")
+			output.WriteString("| --- | --- | --- | --- |\n")
+			for _, result := range results {
+				name := result.Name
+				if _, n, found := strings.Cut(result.Name, "/"); found {
+					name = n
+				}
+				texts := make([]string, 0, len(result.Error))
+				for _, failure := range result.Error {
+					texts = append(texts, fmt.Sprintf("**%s** %s", failure.GetSeverity(), markdownCell(failure.Text)))
+				}
+				output.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+					markdownCell(name),
+					markdownCell(result.ParentObject),
+					strings.Join(texts, "<br>"),
+					markdownCell(result.Details)))
+			}
+		}
+	}
+}
+
+// markdownCell escapes text so it fits in a single markdown table cell.
+func markdownCell(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, "|", "\\|")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
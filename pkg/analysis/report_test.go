@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func reportAnalysis() *Analysis {
+	return &Analysis{
+		Explain:            true,
+		AnalysisAIProvider: "openai",
+		Results: []common.Result{
+			{
+				Kind:         "Pod",
+				Name:         "default/web-abcde",
+				ParentObject: "Deployment/web",
+				Analyzer:     "Pod",
+				Error: []common.Failure{
+					{Text: "back-off restarting failed container", Severity: common.SeverityCritical},
+					{Text: "readiness probe failed", Severity: common.SeverityHigh},
+				},
+				Details: "Error: the container crashes.\nSolution: fix | the command.",
+			},
+			{
+				Kind:     "Pod",
+				Name:     "default/privileged",
+				Analyzer: "PrivilegedContainer",
+				Error:    []common.Failure{{Text: "Container app is running as a privileged container"}},
+			},
+			{
+				Kind:     "Node",
+				Name:     "node-1",
+				Analyzer: "Node",
+				Error:    []common.Failure{{Text: "node is not ready"}},
+			},
+		},
+		Errors: []string{"test-warning"},
+	}
+}
+
+func TestJunitOutput(t *testing.T) {
+	output, err := reportAnalysis().PrintOutput("junit")
+	require.NoError(t, err)
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(output, &report))
+	require.Equal(t, 3, report.Tests)
+	require.Equal(t, 3, report.Failures)
+	require.Len(t, report.TestSuites, 4)
+
+	names := []string{}
+	for _, suite := range report.TestSuites {
+		names = append(names, suite.Name)
+	}
+	require.Equal(t, []string{"Node", "Pod", "PrivilegedContainer", "k8sgpt"}, names)
+
+	pod := report.TestSuites[1]
+	require.Equal(t, 1, pod.Failures)
+	require.Equal(t, "default/web-abcde", pod.TestCases[0].Name)
+	require.Equal(t, "Pod", pod.TestCases[0].ClassName)
+	require.Equal(t, "critical", pod.TestCases[0].Failure.Type)
+	require.Equal(t, "back-off restarting failed container; readiness probe failed", pod.TestCases[0].Failure.Message)
+	require.Contains(t, pod.TestCases[0].Failure.Text, "Parent: Deployment/web")
+	require.Contains(t, pod.TestCases[0].Failure.Text, "- [high] readiness probe failed")
+	require.Contains(t, pod.TestCases[0].Failure.Text, "Solution: fix | the command.")
+
+	require.Equal(t, "test-warning", report.TestSuites[3].SystemErr)
+}
+
+func TestMarkdownOutput(t *testing.T) {
+	output, err := reportAnalysis().PrintOutput("markdown")
+	require.NoError(t, err)
+
+	require.Equal(t, `# K8sGPT analysis
+
+AI Provider: `+"`openai`"+`
+
+**Warnings**
+
+- test-warning
+
+4 problems detected in 3 resources
+
+## Cluster scoped
+
+### Node
+
+| Name | Parent | Errors | Details |
+| --- | --- | --- | --- |
+| node-1 |  | **medium** node is not ready |  |
+
+## Namespace `+"`default`"+`
+
+### Pod
+
+| Name | Parent | Errors | Details |
+| --- | --- | --- | --- |
+| privileged |  | **medium** Container app is running as a privileged container |  |
+| web-abcde | Deployment/web | **critical** back-off restarting failed container<br>**high** readiness probe failed | Error: the container crashes.<br>Solution: fix \| the command. |
+`, string(output))
+
+	output, err = (&Analysis{}).PrintOutput("markdown")
+	require.NoError(t, err)
+	require.Equal(t, "# K8sGPT analysis\n\nNo problems detected\n", string(output))
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/spf13/viper"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	// sarifFingerprintKey versions the partial fingerprints, so consumers can
+	// tell them apart should the fingerprint algorithm ever change.
+	sarifFingerprintKey = "k8sgpt/v1"
+)
+
+// SARIF 2.1.0 log, limited to the properties k8sgpt populates.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Results     []sarifResult     `json:"results"`
+	Invocations []sarifInvocation `json:"invocations,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+// sarifLevel maps a severity to a SARIF level.
+func sarifLevel(severity common.Severity) string {
+	switch {
+	case severity.AtLeast(common.SeverityHigh):
+		return "error"
+	case severity.AtLeast(common.SeverityMedium):
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// ruleID returns the analyzer that produced the result. Results loaded from
+// older outputs do not record it, their Kind is the closest match.
+func ruleID(result common.Result) string {
+	if result.Analyzer != "" {
+		return result.Analyzer
+	}
+	return result.Kind
+}
+
+func (a *Analysis) sarifOutput() ([]byte, error) {
+	// The default level of a rule is the level of its most severe result.
+	ruleSeverities := map[string]common.Severity{}
+	for _, result := range a.Results {
+		id := ruleID(result)
+		severity := result.HighestSeverity()
+		if current, ok := ruleSeverities[id]; !ok || severity.AtLeast(current) {
+			ruleSeverities[id] = severity
+		}
+	}
+	ids := make([]string, 0, len(ruleSeverities))
+	for id := range ruleSeverities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	rules := make([]sarifRule, 0, len(ids))
+	ruleIndex := map[string]int{}
+	for i, id := range ids {
+		ruleIndex[id] = i
+		rules = append(rules, sarifRule{
+			ID:                   id,
+			Name:                 id,
+			ShortDescription:     sarifMessage{Text: fmt.Sprintf("Problems detected by the k8sgpt %s analyzer", id)},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(ruleSeverities[id])},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, result := range a.Results {
+		id := ruleID(result)
+		location := sarifLocation{LogicalLocations: []sarifLogicalLocation{resourceLocation(result)}}
+		for _, failure := range result.Error {
+			r := sarifResult{
+				RuleID:    id,
+				RuleIndex: ruleIndex[id],
+				Level:     sarifLevel(failure.GetSeverity()),
+				Message:   sarifMessage{Text: failure.Text},
+				Locations: []sarifLocation{location},
+			}
+			if failure.Fingerprint != "" {
+				r.PartialFingerprints = map[string]string{sarifFingerprintKey: failure.Fingerprint}
+			}
+			properties := map[string]string{"severity": string(failure.GetSeverity())}
+			if result.ParentObject != "" {
+				properties["parentObject"] = result.ParentObject
+			}
+			if failure.KubernetesDoc != "" {
+				properties["kubernetesDoc"] = failure.KubernetesDoc
+			}
+			if result.Details != "" {
+				properties["details"] = result.Details
+			}
+			r.Properties = properties
+			results = append(results, r)
+		}
+	}
+
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "k8sgpt",
+			Version:        viper.GetString("Version"),
+			InformationURI: "https://k8sgpt.ai",
+			Rules:          rules,
+		}},
+		Results: results,
+	}
+	if len(a.Errors) != 0 {
+		invocation := sarifInvocation{ExecutionSuccessful: true}
+		for _, aerror := range a.Errors {
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+				Level:   "warning",
+				Message: sarifMessage{Text: aerror},
+			})
+		}
+		run.Invocations = []sarifInvocation{invocation}
+	}
+
+	output, err := json.MarshalIndent(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling sarif: %v", err)
+	}
+	return output, nil
+}
+
+// resourceLocation encodes the Kubernetes object as a SARIF logical location,
+// e.g. "default/Pod/example" for the namespaced Pod default/example, prefixed
+// by the cluster when analyzing several clusters.
+func resourceLocation(result common.Result) sarifLogicalLocation {
+	name := result.Name
+	fullyQualifiedName := result.Kind + "/" + result.Name
+	if namespace, n, found := strings.Cut(result.Name, "/"); found {
+		name = n
+		fullyQualifiedName = namespace + "/" + result.Kind + "/" + n
+	}
+	if result.Cluster != "" {
+		fullyQualifiedName = result.Cluster + "/" + fullyQualifiedName
+	}
+	return sarifLogicalLocation{
+		Name:               name,
+		FullyQualifiedName: fullyQualifiedName,
+		Kind:               "resource",
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSarifOutput(t *testing.T) {
+	analysis := Analysis{
+		Results: []common.Result{
+			{
+				Kind:     "Pod",
+				Name:     "default/example",
+				Analyzer: "PrivilegedContainer",
+				Error: []common.Failure{
+					{Text: "Container app in Pod example is running as a privileged container", Severity: common.SeverityCritical, Fingerprint: "0123456789abcdef"},
+				},
+			},
+			{
+				Kind:         "Pod",
+				Name:         "default/example",
+				ParentObject: "Deployment/example",
+				Analyzer:     "Pod",
+				Error:        []common.Failure{{Text: "test-problem", Severity: common.SeverityLow}},
+				Details:      "test-solution",
+			},
+			{
+				Kind:  "Node",
+				Name:  "node-1",
+				Error: []common.Failure{{Text: "node is not ready"}},
+			},
+		},
+		Errors: []string{"test-warning"},
+	}
+
+	output, err := analysis.PrintOutput("sarif")
+	require.NoError(t, err)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(output, &log))
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	run := log.Runs[0]
+	require.Equal(t, "k8sgpt", run.Tool.Driver.Name)
+	require.Equal(t, []string{"Node", "Pod", "PrivilegedContainer"}, []string{
+		run.Tool.Driver.Rules[0].ID, run.Tool.Driver.Rules[1].ID, run.Tool.Driver.Rules[2].ID,
+	})
+	require.Len(t, run.Results, 3)
+
+	privileged := run.Results[0]
+	require.Equal(t, "PrivilegedContainer", privileged.RuleID)
+	require.Equal(t, 2, privileged.RuleIndex)
+	require.Equal(t, "error", privileged.Level)
+	require.Equal(t, "error", run.Tool.Driver.Rules[2].DefaultConfiguration.Level)
+	require.Equal(t, map[string]string{"k8sgpt/v1": "0123456789abcdef"}, privileged.PartialFingerprints)
+	require.Equal(t, []sarifLogicalLocation{{Name: "example", FullyQualifiedName: "default/Pod/example", Kind: "resource"}},
+		privileged.Locations[0].LogicalLocations)
+
+	pod := run.Results[1]
+	require.Equal(t, "note", pod.Level)
+	require.Equal(t, map[string]string{"severity": "low", "parentObject": "Deployment/example", "details": "test-solution"}, pod.Properties)
+
+	node := run.Results[2]
+	require.Equal(t, "Node", node.RuleID)
+	// Failures without a severity are considered medium.
+	require.Equal(t, "warning", node.Level)
+	require.Equal(t, "Node/node-1", node.Locations[0].LogicalLocations[0].FullyQualifiedName)
+
+	require.Equal(t, "test-warning", run.Invocations[0].ToolExecutionNotifications[0].Message.Text)
+}
+
+func TestSarifOutput_NoResults(t *testing.T) {
+	output, err := (&Analysis{}).PrintOutput("sarif")
+	require.NoError(t, err)
+	require.Contains(t, string(output), `"results": []`)
+	require.Contains(t, string(output), `"rules": []`)
+}
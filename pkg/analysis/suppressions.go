@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSuppressionsFile is read from the working directory when no
+// suppressions file is configured.
+const DefaultSuppressionsFile = ".k8sgptignore"
+
+// SuppressionsFile lists accepted problems that are dropped from the results.
+type SuppressionsFile struct {
+	Suppressions []Suppression `yaml:"suppressions"`
+}
+
+// Suppression matches problems either by fingerprint, or by all of its
+// non-empty Kind, Namespace (glob), Name and Failure (regular expressions)
+// fields. Expired suppressions no longer apply.
+type Suppression struct {
+	Fingerprint string `yaml:"fingerprint,omitempty"`
+	Kind        string `yaml:"kind,omitempty"`
+	Namespace   string `yaml:"namespace,omitempty"`
+	Name        string `yaml:"name,omitempty"`
+	Failure     string `yaml:"failure,omitempty"`
+	Owner       string `yaml:"owner"`
+	Reason      string `yaml:"reason"`
+	// Expires is a date (2006-01-02) or an RFC 3339 timestamp.
+	Expires string `yaml:"expires,omitempty"`
+}
+
+type suppressions struct {
+	path  string
+	rules []suppressionRule
+}
+
+type suppressionRule struct {
+	fingerprint string
+	kind        string
+	namespace   string
+	name        *regexp.Regexp
+	failure     *regexp.Regexp
+}
+
+// loadSuppressions reads a suppressions file. Expired suppressions are
+// skipped and returned as warnings.
+func loadSuppressions(file string, now time.Time) (*suppressions, []string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	var config SuppressionsFile
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("error parsing suppressions file %s: %w", file, err)
+	}
+
+	s := &suppressions{path: file}
+	var warnings []string
+	for i, suppression := range config.Suppressions {
+		rule, expired, err := suppression.compile(now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid suppression %d in %s: %w", i, file, err)
+		}
+		if expired {
+			warnings = append(warnings, fmt.Sprintf("suppression %d in %s (owner: %s, reason: %s) expired on %s",
+				i, file, suppression.Owner, suppression.Reason, suppression.Expires))
+			continue
+		}
+		s.rules = append(s.rules, rule)
+	}
+	return s, warnings, nil
+}
+
+func (s Suppression) compile(now time.Time) (suppressionRule, bool, error) {
+	rule := suppressionRule{
+		fingerprint: s.Fingerprint,
+		kind:        s.Kind,
+		namespace:   s.Namespace,
+	}
+	if s.Owner == "" || s.Reason == "" {
+		return rule, false, errors.New("owner and reason are required")
+	}
+	if s.Fingerprint == "" && s.Kind == "" && s.Namespace == "" && s.Name == "" && s.Failure == "" {
+		return rule, false, errors.New("a fingerprint, kind, namespace, name or failure is required")
+	}
+	if _, err := path.Match(s.Namespace, ""); err != nil {
+		return rule, false, fmt.Errorf("invalid namespace: %w", err)
+	}
+
+	var err error
+	if s.Name != "" {
+		if rule.name, err = regexp.Compile("^(?:" + s.Name + ")$"); err != nil {
+			return rule, false, fmt.Errorf("invalid name: %w", err)
+		}
+	}
+	if s.Failure != "" {
+		if rule.failure, err = regexp.Compile(s.Failure); err != nil {
+			return rule, false, fmt.Errorf("invalid failure: %w", err)
+		}
+	}
+
+	if s.Expires == "" {
+		return rule, false, nil
+	}
+	expires, err := time.Parse(time.RFC3339, s.Expires)
+	if err != nil {
+		date, dateErr := time.ParseInLocation(time.DateOnly, s.Expires, time.Local)
+		if dateErr != nil {
+			return rule, false, fmt.Errorf("invalid expiry %q, use 2006-01-02 or RFC 3339", s.Expires)
+		}
+		// A suppression is valid until the end of its expiry date.
+		expires = date.AddDate(0, 0, 1)
+	}
+	return rule, !now.Before(expires), nil
+}
+
+func (r suppressionRule) matches(result common.Result, failure common.Failure) bool {
+	if r.fingerprint != "" {
+		return r.fingerprint == result.Fingerprint || r.fingerprint == failure.Fingerprint
+	}
+
+	namespace, name, found := strings.Cut(result.Name, "/")
+	if !found {
+		namespace, name = "", result.Name
+	}
+	if r.kind != "" && r.kind != result.Kind {
+		return false
+	}
+	if r.namespace != "" {
+		if ok, _ := path.Match(r.namespace, namespace); !ok {
+			return false
+		}
+	}
+	if r.name != nil && !r.name.MatchString(name) {
+		return false
+	}
+	return r.failure == nil || r.failure.MatchString(failure.Text)
+}
+
+// apply drops the suppressed failures, and the results left without any, and
+// returns the number of suppressed failures.
+func (s *suppressions) apply(results []common.Result) ([]common.Result, int) {
+	if s == nil || len(s.rules) == 0 {
+		return results, 0
+	}
+
+	var suppressed int
+	kept := results[:0]
+	for _, result := range results {
+		var failures []common.Failure
+		for _, failure := range result.Error {
+			if s.suppresses(result, failure) {
+				suppressed++
+			} else {
+				failures = append(failures, failure)
+			}
+		}
+		if len(failures) > 0 {
+			result.Error = failures
+			kept = append(kept, result)
+		}
+	}
+	return kept, suppressed
+}
+
+func (s *suppressions) suppresses(result common.Result, failure common.Failure) bool {
+	for _, rule := range s.rules {
+		if rule.matches(result, failure) {
+			return true
+		}
+	}
+	return false
+}
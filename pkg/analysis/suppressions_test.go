@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSuppressions(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), ".k8sgptignore")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestSuppressions(t *testing.T) {
+	path := writeSuppressions(t, `suppressions:
+- fingerprint: 0123456789abcdef
+  owner: team-payments
+  reason: legacy service, decommissioned in Q3
+- kind: Pod
+  namespace: monitoring
+  name: node-exporter-.*
+  failure: privileged container
+  owner: team-platform
+  reason: node-exporter needs host access
+  expires: 2030-01-31
+- kind: Pod
+  failure: running as root
+  owner: team-platform
+  reason: accepted until the base image is fixed
+  expires: 2020-01-31
+`)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	s, warnings, err := loadSuppressions(path, now)
+	require.NoError(t, err)
+	require.Len(t, s.rules, 2)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "(owner: team-platform, reason: accepted until the base image is fixed) expired on 2020-01-31")
+
+	results, suppressed := s.apply([]common.Result{
+		{
+			Kind:        "Service",
+			Name:        "default/legacy",
+			Fingerprint: "0123456789abcdef",
+			Error:       []common.Failure{{Text: "Service has no endpoints"}},
+		},
+		{
+			Kind: "Pod",
+			Name: "monitoring/node-exporter-abcde",
+			Error: []common.Failure{
+				{Text: "Container app in Pod node-exporter-abcde is running as a privileged container"},
+				{Text: "Container app in Pod node-exporter-abcde is running as root user"},
+			},
+		},
+		{
+			Kind:  "Pod",
+			Name:  "default/node-exporter-abcde",
+			Error: []common.Failure{{Text: "Container app in Pod node-exporter-abcde is running as a privileged container"}},
+		},
+	})
+	require.Equal(t, 2, suppressed)
+	require.Len(t, results, 2)
+	require.Equal(t, []common.Failure{{Text: "Container app in Pod node-exporter-abcde is running as root user"}}, results[0].Error)
+	require.Equal(t, "default/node-exporter-abcde", results[1].Name)
+}
+
+func TestSuppressions_Invalid(t *testing.T) {
+	now := time.Now()
+	tests := map[string]string{
+		"owner and reason are required": `suppressions:
+- kind: Pod
+`,
+		"a fingerprint, kind, namespace, name or failure is required": `suppressions:
+- owner: me
+  reason: everything
+`,
+		"invalid expiry": `suppressions:
+- kind: Pod
+  owner: me
+  reason: because
+  expires: next week
+`,
+	}
+	for expected, content := range tests {
+		_, _, err := loadSuppressions(writeSuppressions(t, content), now)
+		require.ErrorContains(t, err, expected)
+	}
+}
+
+func TestAnalysis_LoadSuppressions(t *testing.T) {
+	a := &Analysis{
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/example", Error: []common.Failure{{Text: "test-problem"}}},
+		},
+	}
+	require.NoError(t, a.LoadSuppressions(writeSuppressions(t, `suppressions:
+- kind: Pod
+  owner: me
+  reason: because
+`)))
+
+	a.Results, a.Suppressed = a.suppressions.apply(a.Results)
+	output, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	require.Contains(t, string(output), `"suppressed": 1`)
+	require.Contains(t, string(output), `"results": []`)
+}
+
+func TestNewAnalysis_Suppressions(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := filepath.Join(dir, "snapshot.yaml")
+	require.NoError(t, os.WriteFile(snapshot, []byte("apiVersion: v1\nkind: List\nitems: []\n"), 0600))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	require.NoError(t, os.WriteFile(DefaultSuppressionsFile, []byte("suppressions: [{kind: Pod}]\n"), 0600))
+
+	// The default file is loaded when no file is given.
+	_, err = NewAnalysis("", "english", nil, "", "", true, false, 1, false, false, nil, false, snapshot, nil, "")
+	require.ErrorContains(t, err, "owner and reason are required")
+
+	// Only the file given is loaded otherwise.
+	path := writeSuppressions(t, `suppressions:
+- kind: Pod
+  owner: me
+  reason: because
+  expires: 2000-01-01
+`)
+	a, err := NewAnalysis("", "english", nil, "", "", true, false, 1, false, false, nil, false, snapshot, nil, path)
+	require.NoError(t, err)
+	require.Len(t, a.Errors, 1)
+	require.Contains(t, a.Errors[0], "expired on 2000-01-01")
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	AIRequestsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ai_requests_total",
+		Help: "Number of completions requested from the AI providers",
+	}, []string{"provider"})
+	AITokensMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ai_tokens_total",
+		Help: "Number of tokens used by the AI providers, estimated if not reported",
+	}, []string{"provider", "type"})
+	AICostMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "ai_cost_total",
+		Help: "Cost of the completions requested from the AI providers, in the currency of the configured prices",
+	}, []string{"provider"})
+)
+
+// AIUsage sums the usage of an AI provider during an analysis.
+type AIUsage struct {
+	Provider         string `json:"provider"`
+	Calls            int    `json:"calls"`
+	PromptTokens     int    `json:"promptTokens"`
+	CompletionTokens int    `json:"completionTokens"`
+	// EstimatedCalls is the number of calls whose usage the provider did not
+	// report, and was estimated.
+	EstimatedCalls int `json:"estimatedCalls,omitempty"`
+	// Cost is only computed for the providers with prices.
+	Cost float64 `json:"cost,omitempty"`
+}
+
+type aiUsage struct {
+	mutex     sync.Mutex
+	providers map[string]*AIUsage
+}
+
+func newAIUsage() *aiUsage {
+	return &aiUsage{providers: map[string]*AIUsage{}}
+}
+
+// recordUsage adds the usage of a completion to the analysis and to the
+// metrics.
+func (a *Analysis) recordUsage(completion ai.CompletionResult) {
+	cost := a.pricing[completion.Provider].Cost(completion.Usage)
+
+	AIRequestsMetric.WithLabelValues(completion.Provider).Inc()
+	AITokensMetric.WithLabelValues(completion.Provider, "prompt").Add(float64(completion.Usage.PromptTokens))
+	AITokensMetric.WithLabelValues(completion.Provider, "completion").Add(float64(completion.Usage.CompletionTokens))
+	AICostMetric.WithLabelValues(completion.Provider).Add(cost)
+
+	if a.usage == nil {
+		return
+	}
+	a.usage.mutex.Lock()
+	defer a.usage.mutex.Unlock()
+	usage, ok := a.usage.providers[completion.Provider]
+	if !ok {
+		usage = &AIUsage{Provider: completion.Provider}
+		a.usage.providers[completion.Provider] = usage
+	}
+	usage.Calls++
+	usage.PromptTokens += completion.Usage.PromptTokens
+	usage.CompletionTokens += completion.Usage.CompletionTokens
+	if completion.Usage.Estimated {
+		usage.EstimatedCalls++
+	}
+	usage.Cost += cost
+}
+
+// AIUsage returns the usage of every AI provider called during the analysis,
+// sorted by provider.
+func (a *Analysis) AIUsage() []AIUsage {
+	if a.usage == nil {
+		return nil
+	}
+	a.usage.mutex.Lock()
+	defer a.usage.mutex.Unlock()
+	usages := make([]AIUsage, 0, len(a.usage.providers))
+	for _, usage := range a.usage.providers {
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].Provider < usages[j].Provider
+	})
+	return usages
+}
+
+// writeUsage writes the usage summary of the text output and of the stats.
+func (a *Analysis) writeUsage(output *strings.Builder) {
+	usages := a.AIUsage()
+	if len(usages) == 0 {
+		return
+	}
+	output.WriteString(color.YellowString("AI usage:\n"))
+	for _, usage := range usages {
+		output.WriteString(fmt.Sprintf("- %s: %d calls, %d prompt tokens, %d completion tokens",
+			color.YellowString(usage.Provider), usage.Calls, usage.PromptTokens, usage.CompletionTokens))
+		if usage.Cost > 0 {
+			output.WriteString(fmt.Sprintf(", cost %.4f", usage.Cost))
+		}
+		if usage.EstimatedCalls > 0 {
+			output.WriteString(fmt.Sprintf(" (estimated for %d calls)", usage.EstimatedCalls))
+		}
+		output.WriteString("\n")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/ai"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/cache"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIUsage(t *testing.T) {
+	disabledCache := cache.New("disabled-cache")
+	disabledCache.DisableCache()
+	a := Analysis{
+		Context:  context.Background(),
+		AIClient: &ai.NoOpAIClient{},
+		Cache:    disabledCache,
+		Explain:  true,
+		pricing:  map[string]ai.Pricing{"noopai": {InputCost: 1000, OutputCost: 2000}},
+		Results: []common.Result{
+			{Kind: "Pod", Name: "default/a", Error: []common.Failure{{Text: "first failure"}}},
+			{Kind: "Pod", Name: "default/b", Error: []common.Failure{{Text: "second failure"}}},
+		},
+	}
+	requests := testutil.ToFloat64(AIRequestsMetric.WithLabelValues("noopai"))
+
+	require.NoError(t, a.GetAIResults("json", false))
+	usage := a.AIUsage()
+	require.Len(t, usage, 1)
+	require.Equal(t, "noopai", usage[0].Provider)
+	require.Equal(t, 2, usage[0].Calls)
+	require.Equal(t, 2, usage[0].EstimatedCalls)
+	require.Positive(t, usage[0].PromptTokens)
+	require.Positive(t, usage[0].CompletionTokens)
+	require.InDelta(t, float64(usage[0].PromptTokens)/1000+float64(usage[0].CompletionTokens)/500, usage[0].Cost, 1e-9)
+	require.Equal(t, requests+2, testutil.ToFloat64(AIRequestsMetric.WithLabelValues("noopai")))
+
+	output, err := a.PrintOutput("json")
+	require.NoError(t, err)
+	var jsonOutput JsonOutput
+	require.NoError(t, json.Unmarshal(output, &jsonOutput))
+	require.Equal(t, usage, jsonOutput.Usage)
+
+	output, err = a.PrintOutput("text")
+	require.NoError(t, err)
+	require.Contains(t, string(output), "AI usage:\n- noopai: 2 calls")
+	require.Contains(t, string(a.PrintStats()), "(estimated for 2 calls)")
+}
@@ -0,0 +1,324 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+type WatchEventType string
+
+const (
+	WatchEventNew          WatchEventType = "new"
+	WatchEventStillFailing WatchEventType = "still failing"
+	WatchEventResolved     WatchEventType = "resolved"
+	WatchEventWarning      WatchEventType = "warning"
+)
+
+// WatchEvent reports how a single finding changed between two evaluations of
+// its analyzer. Warning events carry a Message instead of a Result.
+type WatchEvent struct {
+	Type     WatchEventType `json:"type"`
+	Analyzer string         `json:"analyzer"`
+	Result   *common.Result `json:"result,omitempty"`
+	Message  string         `json:"message,omitempty"`
+}
+
+type watchState struct {
+	mutex sync.Mutex
+	// dirty holds the analyzers to re-evaluate on the next tick.
+	dirty map[string]struct{}
+	// changed holds the "namespace/name" of every object changed since the last tick.
+	changed map[string]struct{}
+}
+
+func (s *watchState) markChanged(obj interface{}, analyzers []string) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.changed[key] = struct{}{}
+	for _, name := range analyzers {
+		s.dirty[name] = struct{}{}
+	}
+}
+
+func (s *watchState) take() (map[string]struct{}, map[string]struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	dirty, changed := s.dirty, s.changed
+	s.dirty, s.changed = map[string]struct{}{}, map[string]struct{}{}
+	return dirty, changed
+}
+
+// Watch analyzes the cluster once and then keeps re-evaluating the analyzers
+// whose input objects change, calling onEvent for every new, still failing
+// and resolved finding. Changes are batched and evaluated every interval.
+// Analyzers reading resources that cannot be watched are re-evaluated on
+// every interval. Watch blocks until ctx is cancelled.
+func (a *Analysis) Watch(ctx context.Context, interval time.Duration, anonymize bool, onEvent func(WatchEvent)) error {
+	if len(a.clusters) > 0 || len(a.namespaces()) > 1 {
+		return fmt.Errorf("watch mode supports a single cluster and namespace")
+	}
+	analyzers := a.selectedAnalyzers()
+	for _, e := range a.Errors {
+		onEvent(WatchEvent{Type: WatchEventWarning, Message: e})
+	}
+
+	names := make([]string, 0, len(analyzers))
+	for name := range analyzers {
+		names = append(names, name)
+	}
+	resourceAnalyzers, polled := analyzer.GetResourceAnalyzers(names)
+
+	state := &watchState{dirty: map[string]struct{}{}, changed: map[string]struct{}{}}
+	factory := informers.NewSharedInformerFactoryWithOptions(a.Client.GetClient(), 0, informers.WithNamespace(a.Namespace))
+	for gvr, dependents := range resourceAnalyzers {
+		informer, err := factory.ForResource(gvr)
+		if err != nil {
+			// Not a built-in resource (e.g. Gateway API or an integration), poll instead.
+			polled = append(polled, dependents...)
+			continue
+		}
+		dependents := dependents
+		if _, err := informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { state.markChanged(obj, dependents) },
+			UpdateFunc: func(_, obj interface{}) { state.markChanged(obj, dependents) },
+			DeleteFunc: func(obj interface{}) { state.markChanged(obj, dependents) },
+		}); err != nil {
+			return err
+		}
+	}
+
+	factory.Start(ctx.Done())
+	defer factory.Shutdown()
+	for informerType, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to sync informer for %s", informerType)
+		}
+	}
+	// The initial list is reported by the first full evaluation below.
+	_, _ = state.take()
+
+	w := &watcher{
+		analysis:  a,
+		analyzers: analyzers,
+		config:    a.analyzerConfig(),
+		previous:  map[string]map[string]common.Result{},
+		anonymize: anonymize,
+		onEvent:   onEvent,
+	}
+	all := map[string]struct{}{}
+	for _, name := range names {
+		all[name] = struct{}{}
+	}
+	w.evaluate(all, map[string]struct{}{})
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			dirty, changed := state.take()
+			for _, name := range polled {
+				dirty[name] = struct{}{}
+			}
+			if len(dirty) > 0 {
+				w.evaluate(dirty, changed)
+			}
+		}
+	}
+}
+
+type watcher struct {
+	analysis  *Analysis
+	analyzers map[string]common.IAnalyzer
+	config    common.Analyzer
+	// previous holds the last results of every analyzer, keyed by Kind/Name.
+	previous  map[string]map[string]common.Result
+	anonymize bool
+	onEvent   func(WatchEvent)
+}
+
+func (w *watcher) evaluate(dirty map[string]struct{}, changed map[string]struct{}) {
+	results := map[string][]common.Result{}
+	errs := map[string]error{}
+
+	semaphore := make(chan struct{}, max(w.analysis.MaxConcurrency, 1))
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	for name := range dirty {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			r, err := w.analyzers[name].Analyze(w.config)
+			for i := range r {
+				r[i].Analyzer = name
+				r[i].SetFingerprints()
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				errs[name] = err
+				return
+			}
+			results[name] = r
+		}(name)
+	}
+	wg.Wait()
+
+	names := make([]string, 0, len(dirty))
+	for name := range dirty {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err, ok := errs[name]; ok {
+			w.onEvent(WatchEvent{Type: WatchEventWarning, Analyzer: name, Message: fmt.Sprintf("[%s] %s", name, err)})
+			continue
+		}
+		r, _ := w.analysis.suppressions.apply(w.analysis.exclusions.filter(w.analysis.Context, results[name], w.analysis.MaxConcurrency))
+		w.diff(name, r, changed)
+	}
+}
+
+func (w *watcher) diff(name string, results []common.Result, changed map[string]struct{}) {
+	previous := w.previous[name]
+	current := map[string]common.Result{}
+
+	sort.Slice(results, func(i, j int) bool {
+		return resultKey(results[i]) < resultKey(results[j])
+	})
+	for _, result := range results {
+		key := resultKey(result)
+		old, found := previous[key]
+		if !found {
+			if w.analysis.Explain && w.analysis.AIClient != nil {
+				details, provider, err := w.analysis.explainResult(result, w.anonymize, nil)
+				if err != nil {
+					w.onEvent(WatchEvent{Type: WatchEventWarning, Analyzer: name, Message: fmt.Sprintf("failed while calling AI provider %s: %v", w.analysis.AIClient.GetName(), err)})
+				} else {
+					result.Details = details
+					result.Provider = provider
+				}
+			}
+			current[key] = result
+			r := result
+			w.onEvent(WatchEvent{Type: WatchEventNew, Analyzer: name, Result: &r})
+			continue
+		}
+
+		// Keep the explanation of findings that are still failing.
+		result.Details = old.Details
+		result.Provider = old.Provider
+		current[key] = result
+		if _, ok := changed[result.Name]; ok {
+			r := result
+			w.onEvent(WatchEvent{Type: WatchEventStillFailing, Analyzer: name, Result: &r})
+		}
+	}
+
+	keys := make([]string, 0, len(previous))
+	for key := range previous {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := current[key]; !ok {
+			r := previous[key]
+			w.onEvent(WatchEvent{Type: WatchEventResolved, Analyzer: name, Result: &r})
+		}
+	}
+
+	w.previous[name] = current
+}
+
+func resultKey(result common.Result) string {
+	return result.Kind + "/" + result.Name
+}
+
+// CheckWatchOutput reports whether watch events can be rendered in the given
+// output format, to be checked before watching.
+func CheckWatchOutput(format string) error {
+	if format != "json" && format != "text" {
+		return fmt.Errorf("unsupported output format: %s. Available format json,text", format)
+	}
+	return nil
+}
+
+// PrintWatchEvent renders a watch event in the given output format.
+func PrintWatchEvent(format string, event WatchEvent) ([]byte, error) {
+	if err := CheckWatchOutput(format); err != nil {
+		return nil, err
+	}
+	if format == "json" {
+		output, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling json: %v", err)
+		}
+		return output, nil
+	}
+
+	if event.Type == WatchEventWarning {
+		return []byte(color.YellowString("[%s] %s", event.Type, event.Message)), nil
+	}
+
+	var output strings.Builder
+	label := fmt.Sprintf("[%s]", event.Type)
+	switch event.Type {
+	case WatchEventNew:
+		label = color.RedString(label)
+	case WatchEventStillFailing:
+		label = color.YellowString(label)
+	case WatchEventResolved:
+		label = color.GreenString(label)
+	}
+	result := event.Result
+	output.WriteString(fmt.Sprintf("%s %s %s(%s)\n", label,
+		color.HiYellowString(result.Kind),
+		color.YellowString(result.Name),
+		color.CyanString(result.ParentObject)))
+	if event.Type != WatchEventResolved {
+		for _, err := range result.Error {
+			output.WriteString(fmt.Sprintf("- %s %s %s\n", color.RedString("Error:"), severityLabel(err.GetSeverity()), color.RedString(err.Text)))
+		}
+		if result.Details != "" && event.Type == WatchEventNew {
+			output.WriteString(color.GreenString(result.Details + "\n"))
+		}
+	}
+	return []byte(strings.TrimSuffix(output.String(), "\n")), nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analysis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func pendingPod(name string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+			Conditions: []v1.PodCondition{
+				{
+					Type:    v1.PodScheduled,
+					Reason:  "Unschedulable",
+					Message: "0/1 nodes are available",
+				},
+			},
+		},
+	}
+}
+
+func nextWatchEvent(t *testing.T, events chan WatchEvent) WatchEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for watch event")
+	}
+	return WatchEvent{}
+}
+
+func TestAnalysis_Watch(t *testing.T) {
+	clientset := fake.NewSimpleClientset(pendingPod("example"))
+	analysis := Analysis{
+		Context:   context.Background(),
+		Filters:   []string{"Pod"},
+		Namespace: "default",
+		// Without a maximum, one analyzer runs at a time.
+		MaxConcurrency: 0,
+		Client: &kubernetes.Client{
+			Client: clientset,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan WatchEvent, 10)
+	done := make(chan error)
+	go func() {
+		done <- analysis.Watch(ctx, 10*time.Millisecond, false, func(e WatchEvent) {
+			events <- e
+		})
+	}()
+
+	event := nextWatchEvent(t, events)
+	require.Equal(t, WatchEventNew, event.Type)
+	require.Equal(t, "default/example", event.Result.Name)
+
+	// A second failing pod is reported as new, the first one is untouched.
+	_, err := clientset.CoreV1().Pods("default").Create(ctx, pendingPod("other"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	event = nextWatchEvent(t, events)
+	require.Equal(t, WatchEventNew, event.Type)
+	require.Equal(t, "default/other", event.Result.Name)
+
+	// Changing a failing pod reports it as still failing.
+	pod := pendingPod("example")
+	pod.Labels = map[string]string{"changed": "true"}
+	_, err = clientset.CoreV1().Pods("default").Update(ctx, pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	event = nextWatchEvent(t, events)
+	require.Equal(t, WatchEventStillFailing, event.Type)
+	require.Equal(t, "default/example", event.Result.Name)
+
+	// Once scheduled, the pod is resolved.
+	pod.Status = v1.PodStatus{Phase: v1.PodRunning}
+	_, err = clientset.CoreV1().Pods("default").Update(ctx, pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	event = nextWatchEvent(t, events)
+	require.Equal(t, WatchEventResolved, event.Type)
+	require.Equal(t, "default/example", event.Result.Name)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestPrintWatchEvent(t *testing.T) {
+	event := WatchEvent{
+		Type:     WatchEventNew,
+		Analyzer: "Pod",
+		Result: &common.Result{
+			Kind:  "Pod",
+			Name:  "default/example",
+			Error: []common.Failure{{Text: "test-problem"}},
+		},
+	}
+
+	output, err := PrintWatchEvent("json", event)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"new","analyzer":"Pod","result":{"kind":"Pod","name":"default/example","error":[{"Text":"test-problem","KubernetesDoc":"","Sensitive":null}],"details":"","parentObject":""}}`, string(output))
+
+	output, err = PrintWatchEvent("text", event)
+	require.NoError(t, err)
+	require.Contains(t, string(output), "default/example")
+	require.Contains(t, string(output), "test-problem")
+
+	_, err = PrintWatchEvent("unsupported", event)
+	require.ErrorContains(t, err, "unsupported output format")
+	require.NoError(t, CheckWatchOutput("json"))
+	require.ErrorContains(t, CheckWatchOutput("sarif"), "unsupported output format")
+}
@@ -49,6 +49,7 @@ func (analyzer AllowedPortsServiceAnalyzer) Analyze(a common.Analyzer) ([]common
 			if !allowedPorts[port.Port] {
 				doc := apiDoc.GetApiDocV2("spec.ports")
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityMedium,
 					Text:          fmt.Sprintf("Service %s is exposing port %d, which is not in the allowed list", service.Name, port.Port),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
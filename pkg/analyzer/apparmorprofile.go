@@ -48,6 +48,7 @@ func (analyzer AppArmorProfileAnalyzer) Analyze(a common.Analyzer) ([]common.Res
 			if !exists || profile == "unconfined" || !analyzer.isAllowedProfile(profile) {
 				doc := apiDoc.GetApiDocV2(fmt.Sprintf("metadata.annotations.%s", profileKey))
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityMedium,
 					Text:          fmt.Sprintf("Container %s in Pod %s is using an unapproved AppArmor profile: %s", container.Name, pod.Name, profile),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -56,6 +56,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 			doc := apiDoc.GetApiDocV2("spec.suspend")
 
 			failures = append(failures, common.Failure{
+				Severity:      common.SeverityInfo,
 				Text:          fmt.Sprintf("CronJob %s is suspended", cronJob.Name),
 				KubernetesDoc: doc,
 				Sensitive: []common.Sensitive{
@@ -75,6 +76,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 				doc := apiDoc.GetApiDocV2("spec.schedule")
 
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("CronJob %s has an invalid schedule: %s", cronJob.Name, err.Error()),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -97,6 +99,7 @@ func (analyzer CronJobAnalyzer) Analyze(a common.Analyzer) ([]common.Result, err
 					doc := apiDoc.GetApiDocV2("spec.startingDeadlineSeconds")
 
 					failures = append(failures, common.Failure{
+						Severity:      common.SeverityMedium,
 						Text:          fmt.Sprintf("CronJob %s has a negative starting deadline", cronJob.Name),
 						KubernetesDoc: doc,
 						Sensitive: []common.Sensitive{
@@ -58,6 +58,7 @@ func (d DeploymentAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 			doc := apiDoc.GetApiDocV2("spec.replicas")
 
 			failures = append(failures, common.Failure{
+				Severity:      common.SeverityHigh,
 				Text:          fmt.Sprintf("Deployment %s/%s has %d replicas but %d are available", deployment.Namespace, deployment.Name, *deployment.Spec.Replicas, deployment.Status.Replicas),
 				KubernetesDoc: doc,
 				Sensitive: []common.Sensitive{
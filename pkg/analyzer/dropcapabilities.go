@@ -44,6 +44,7 @@ func (analyzer DropCapabilitiesAnalyzer) Analyze(a common.Analyzer) ([]common.Re
 
 				doc := apiDoc.GetApiDocV2("spec.containers[*].securityContext.capabilities.drop")
 				failures = append(failures, common.Failure{
+					Severity: common.SeverityMedium,
 					Text: fmt.Sprintf("Container %s in Pod %s does not explicitly drop all capabilities, making it vulnerable to privilege escalation.",
 						container.Name, pod.Name),
 					KubernetesDoc: doc,
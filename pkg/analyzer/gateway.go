@@ -59,6 +59,7 @@ func (GatewayAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		err := client.Get(a.Context, ctrl.ObjectKey{Namespace: gtwNamespace, Name: string(gtw.Spec.GatewayClassName)}, gc, &ctrl.GetOptions{})
 		if errors.IsNotFound(err) {
 			failures = append(failures, common.Failure{
+				Severity: common.SeverityHigh,
 				Text: fmt.Sprintf(
 					"Gateway uses the GatewayClass %s which does not exist.",
 					gtw.Spec.GatewayClassName,
@@ -76,6 +77,7 @@ func (GatewayAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		// TODO: maybe check other statuses Listeners, addresses?
 		if gtw.Status.Conditions[0].Status != metav1.ConditionTrue {
 			failures = append(failures, common.Failure{
+				Severity: common.SeverityHigh,
 				Text: fmt.Sprintf("Gateway '%s/%s' is not accepted. Message: '%s'.",
 					gtwNamespace,
 					gtwName,
@@ -55,6 +55,7 @@ func (GatewayClassAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 		// Check only the current condition
 		if gc.Status.Conditions[0].Status != metav1.ConditionTrue {
 			failures = append(failures, common.Failure{
+				Severity: common.SeverityHigh,
 				Text: fmt.Sprintf(
 					"GatewayClass '%s' with a controller name '%s' is not accepted. Message: '%s'.",
 					gcName,
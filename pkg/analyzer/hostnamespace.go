@@ -52,6 +52,7 @@ func (analyzer HostNamespaceAnalyzer) Analyze(a common.Analyzer) ([]common.Resul
 
 			doc := apiDoc.GetApiDocV2("spec.hostPID / spec.hostIPC / spec.hostNetwork")
 			failures = append(failures, common.Failure{
+				Severity:      common.SeverityHigh,
 				Text:          fmt.Sprintf("Pod %s is sharing sensitive host namespaces: %v", pod.Name, sharedNamespaces),
 				KubernetesDoc: doc,
 				Sensitive: []common.Sensitive{
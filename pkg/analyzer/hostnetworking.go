@@ -41,6 +41,7 @@ func (analyzer HostNetworkingAnalyzer) Analyze(a common.Analyzer) ([]common.Resu
 		if pod.Spec.HostNetwork {
 			doc := apiDoc.GetApiDocV2("spec.hostNetwork")
 			failures = append(failures, common.Failure{
+				Severity:      common.SeverityHigh,
 				Text:          fmt.Sprintf("Pod %s is using host networking, which may expose it to security risks", pod.Name),
 				KubernetesDoc: doc,
 				Sensitive: []common.Sensitive{
@@ -56,6 +57,7 @@ func (analyzer HostNetworkingAnalyzer) Analyze(a common.Analyzer) ([]common.Resu
 				if port.HostPort != 0 {
 					doc := apiDoc.GetApiDocV2("spec.containers.ports.hostPort")
 					failures = append(failures, common.Failure{
+						Severity:      common.SeverityMedium,
 						Text:          fmt.Sprintf("Pod %s uses host port %d, which may pose security risks", pod.Name, port.HostPort),
 						KubernetesDoc: doc,
 						Sensitive: []common.Sensitive{
@@ -54,6 +54,7 @@ func (analyzer HostPathAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 				if !allowed {
 					doc := apiDoc.GetApiDocV2("spec.volumes.hostPath")
 					failures = append(failures, common.Failure{
+						Severity:      common.SeverityHigh,
 						Text:          fmt.Sprintf("Pod %s is using an unapproved HostPath: %s", pod.Name, volume.HostPath.Path),
 						KubernetesDoc: doc,
 						Sensitive: []common.Sensitive{
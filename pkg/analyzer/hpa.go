@@ -58,6 +58,7 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		for _, condition := range conditions {
 			if condition.Status != "True" {
 				failures = append(failures, common.Failure{
+					Severity:  common.SeverityMedium,
 					Text:      condition.Message,
 					Sensitive: []common.Sensitive{},
 				})
@@ -91,6 +92,7 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			}
 		default:
 			failures = append(failures, common.Failure{
+				Severity:  common.SeverityHigh,
 				Text:      fmt.Sprintf("HorizontalPodAutoscaler uses %s as ScaleTargetRef which is not an option.", scaleTargetRef.Kind),
 				Sensitive: []common.Sensitive{},
 			})
@@ -100,6 +102,7 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			doc := apiDoc.GetApiDocV2("spec.scaleTargetRef")
 
 			failures = append(failures, common.Failure{
+				Severity:      common.SeverityHigh,
 				Text:          fmt.Sprintf("HorizontalPodAutoscaler uses %s/%s as ScaleTargetRef which does not exist.", scaleTargetRef.Kind, scaleTargetRef.Name),
 				KubernetesDoc: doc,
 				Sensitive: []common.Sensitive{
@@ -121,6 +124,7 @@ func (HpaAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				doc := apiDoc.GetApiDocV2("spec.scaleTargetRef.kind")
 
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityMedium,
 					Text:          fmt.Sprintf("%s %s/%s does not have resource configured.", scaleTargetRef.Kind, a.Namespace, scaleTargetRef.Name),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
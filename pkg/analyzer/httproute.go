@@ -64,6 +64,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			err := client.Get(a.Context, ctrl.ObjectKey{Namespace: namespace, Name: string(gtwref.Name)}, gtw, &ctrl.GetOptions{})
 			if errors.IsNotFound(err) {
 				failures = append(failures, common.Failure{
+					Severity: common.SeverityHigh,
 					Text: fmt.Sprintf(
 						"HTTPRoute uses the Gateway '%s/%s' which does not exist in the same namespace.",
 						namespace,
@@ -89,6 +90,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 							// check if Gateway is in the same namespace
 							if route.Namespace != gtw.Namespace {
 								failures = append(failures, common.Failure{
+									Severity: common.SeverityHigh,
 									Text: fmt.Sprintf("HTTPRoute '%s/%s' is deployed in a different namespace from Gateway '%s/%s' which only allows HTTPRoutes from its namespace.",
 										route.Namespace,
 										route.Name,
@@ -119,6 +121,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 							// check if our route include the same selector Label
 							if !util.LabelsIncludeAny(listener.AllowedRoutes.Namespaces.Selector.MatchLabels, route.Labels) {
 								failures = append(failures, common.Failure{
+									Severity: common.SeverityHigh,
 									Text: fmt.Sprintf(
 										"HTTPRoute '%s/%s' can't be attached on Gateway '%s/%s', selector labels do not match HTTProute's labels.",
 										route.Namespace,
@@ -160,6 +163,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				err := client.Get(a.Context, ctrl.ObjectKey{Namespace: route.Namespace, Name: string(backend.Name)}, service, &ctrl.GetOptions{})
 				if errors.IsNotFound(err) {
 					failures = append(failures, common.Failure{
+						Severity: common.SeverityHigh,
 						Text: fmt.Sprintf(
 							"HTTPRoute uses the Service '%s/%s' which does not exist.",
 							route.Namespace,
@@ -185,6 +189,7 @@ func (HTTPRouteAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 					}
 					if !portMatch {
 						failures = append(failures, common.Failure{
+							Severity: common.SeverityHigh,
 							Text: fmt.Sprintf(
 								"HTTPRoute's backend service '%s' is using port '%d' but the corresponding K8s service '%s/%s' isn't configured with the same port.",
 								backend.Name,
@@ -42,6 +42,7 @@ func (analyzer HTTPSOnlyServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Re
 			if port.Port == 80 || strings.Contains(strings.ToLower(port.Name), "http") {
 				doc := apiDoc.GetApiDocV2("spec.ports")
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityMedium,
 					Text:          fmt.Sprintf("Service %s is accessible over HTTP on port %d", service.Name, port.Port),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -47,6 +47,7 @@ func (analyzer HTTPSOnlyServiceV2Analyzer) Analyze(a common.Analyzer) ([]common.
 			if (port.Port == 80 || strings.Contains(strings.ToLower(port.Name), "http")) && !nginxSSLConfigEnabled {
 				doc := apiDoc.GetApiDocV2("spec.ports")
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityMedium,
 					Text:          fmt.Sprintf("Service %s is accessible over HTTP on port %d without enforced SSL in Nginx config", service.Name, port.Port),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -59,6 +59,7 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				doc := apiDoc.GetApiDocV2("spec.ingressClassName")
 
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityMedium,
 					Text:          fmt.Sprintf("Ingress %s/%s does not specify an Ingress class.", ing.Namespace, ing.Name),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -84,6 +85,7 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				doc := apiDoc.GetApiDocV2("spec.ingressClassName")
 
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("Ingress uses the ingress class %s which does not exist.", *ingressClassName),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -106,6 +108,7 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 						doc := apiDoc.GetApiDocV2("spec.rules.http.paths.backend.service")
 
 						failures = append(failures, common.Failure{
+							Severity:      common.SeverityHigh,
 							Text:          fmt.Sprintf("Ingress uses the service %s/%s which does not exist.", ing.Namespace, path.Backend.Service.Name),
 							KubernetesDoc: doc,
 							Sensitive: []common.Sensitive{
@@ -130,6 +133,7 @@ func (IngressAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				doc := apiDoc.GetApiDocV2("spec.tls.secretName")
 
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("Ingress uses the secret %s/%s as a TLS certificate which does not exist.", ing.Namespace, tls.SecretName),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
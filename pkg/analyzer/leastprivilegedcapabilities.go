@@ -43,6 +43,7 @@ func (analyzer LeastPrivilegedCapabilitiesAnalyzer) Analyze(a common.Analyzer) (
 				if len(container.SecurityContext.Capabilities.Add) > 0 {
 					doc := apiDoc.GetApiDocV2("spec.containers.securityContext.capabilities.add")
 					failures = append(failures, common.Failure{
+						Severity: common.SeverityMedium,
 						Text: fmt.Sprintf("Container %s in Pod %s has added Linux capabilities: %v, which may violate least privilege principle",
 							container.Name, pod.Name, container.SecurityContext.Capabilities.Add),
 						KubernetesDoc: doc,
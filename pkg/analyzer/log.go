@@ -59,7 +59,8 @@ func (LogAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			podLogs, err := a.Client.Client.CoreV1().Pods(pod.Namespace).GetLogs(podName, &podLogOptions).DoRaw(a.Context)
 			if err != nil {
 				failures = append(failures, common.Failure{
-					Text: fmt.Sprintf("Error %s from Pod %s", err.Error(), pod.Name),
+					Severity: common.SeverityLow,
+					Text:     fmt.Sprintf("Error %s from Pod %s", err.Error(), pod.Name),
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: pod.Name,
@@ -71,7 +72,8 @@ func (LogAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				rawlogs := string(podLogs)
 				if errorPattern.MatchString(strings.ToLower(rawlogs)) {
 					failures = append(failures, common.Failure{
-						Text: printErrorLines(rawlogs, errorPattern),
+						Severity: common.SeverityMedium,
+						Text:     printErrorLines(rawlogs, errorPattern),
 						Sensitive: []common.Sensitive{
 							{
 								Unmasked: pod.Name,
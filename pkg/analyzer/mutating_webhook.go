@@ -62,6 +62,7 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 			if err != nil {
 				// If the service is not found, we can't check the pods
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("Service %s not found as mapped to by Mutating Webhook %s", svc.Name, webhook.Name),
 					KubernetesDoc: apiDoc.GetApiDocV2("spec.webhook.clientConfig.service"),
 					Sensitive: []common.Sensitive{
@@ -97,6 +98,7 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 
 			if len(pods.Items) == 0 {
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("No active pods found within service %s as mapped to by Mutating Webhook %s", svc.Name, webhook.Name),
 					KubernetesDoc: apiDoc.GetApiDocV2("spec.webhook.clientConfig.service"),
 					Sensitive: []common.Sensitive{
@@ -112,6 +114,7 @@ func (MutatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 				if pod.Status.Phase != "Running" {
 					doc := apiDoc.GetApiDocV2("spec.webhook")
 					failures = append(failures, common.Failure{
+						Severity: common.SeverityHigh,
 						Text: fmt.Sprintf(
 							"Mutating Webhook (%s) is pointing to an inactive receiver pod (%s)",
 							webhook.Name,
@@ -58,6 +58,7 @@ func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 			doc := apiDoc.GetApiDocV2("spec.podSelector.matchLabels")
 
 			failures = append(failures, common.Failure{
+				Severity:      common.SeverityMedium,
 				Text:          fmt.Sprintf("Network policy allows traffic to all pods: %s", policy.Name),
 				KubernetesDoc: doc,
 				Sensitive: []common.Sensitive{
@@ -75,7 +76,8 @@ func (NetworkPolicyAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error)
 			}
 			if len(podList.Items) == 0 {
 				failures = append(failures, common.Failure{
-					Text: fmt.Sprintf("Network policy is not applied to any pods: %s", policy.Name),
+					Severity: common.SeverityLow,
+					Text:     fmt.Sprintf("Network policy is not applied to any pods: %s", policy.Name),
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: policy.Name,
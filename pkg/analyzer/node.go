@@ -49,13 +49,13 @@ func (NodeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				if nodeCondition.Status == v1.ConditionTrue {
 					break
 				}
-				failures = addNodeConditionFailure(failures, node.Name, nodeCondition)
+				failures = addNodeConditionFailure(failures, node.Name, nodeCondition, common.SeverityCritical)
 			// k3s `EtcdIsVoter`` should not be reported as an error
 			case v1.NodeConditionType("EtcdIsVoter"):
 				break
 			default:
 				if nodeCondition.Status != v1.ConditionFalse {
-					failures = addNodeConditionFailure(failures, node.Name, nodeCondition)
+					failures = addNodeConditionFailure(failures, node.Name, nodeCondition, common.SeverityHigh)
 				}
 			}
 		}
@@ -87,9 +87,10 @@ func (NodeAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	return a.Results, err
 }
 
-func addNodeConditionFailure(failures []common.Failure, nodeName string, nodeCondition v1.NodeCondition) []common.Failure {
+func addNodeConditionFailure(failures []common.Failure, nodeName string, nodeCondition v1.NodeCondition, severity common.Severity) []common.Failure {
 	failures = append(failures, common.Failure{
-		Text: fmt.Sprintf("%s has condition of type %s, reason %s: %s", nodeName, nodeCondition.Type, nodeCondition.Reason, nodeCondition.Message),
+		Severity: severity,
+		Text:     fmt.Sprintf("%s has condition of type %s, reason %s: %s", nodeName, nodeCondition.Type, nodeCondition.Reason, nodeCondition.Message),
 		Sensitive: []common.Sensitive{
 			{
 				Unmasked: nodeName,
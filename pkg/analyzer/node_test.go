@@ -214,4 +214,6 @@ func TestNodeAnalyzerLabelSelectorFiltering(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, 1, len(results))
 	require.Equal(t, "Node1", results[0].Name)
+	// A node that is not ready is down.
+	require.Equal(t, common.SeverityCritical, results[0].Error[0].Severity)
 }
@@ -41,6 +41,7 @@ func (analyzer NonRootUserAnalyzer) Analyze(a common.Analyzer) ([]common.Result,
 			if container.SecurityContext == nil || container.SecurityContext.RunAsUser == nil {
 				doc := apiDoc.GetApiDocV2("spec.containers.securityContext.runAsUser")
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityMedium,
 					Text:          fmt.Sprintf("Container %s in Pod %s does not specify a non-root user (RunAsUser). It may default to root.", container.Name, pod.Name),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -51,6 +52,7 @@ func (analyzer NonRootUserAnalyzer) Analyze(a common.Analyzer) ([]common.Result,
 			} else if *container.SecurityContext.RunAsUser == 0 {
 				doc := apiDoc.GetApiDocV2("spec.containers.securityContext.runAsUser")
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("Container %s in Pod %s explicitly runs as root (RunAsUser=0).", container.Name, pod.Name),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -66,6 +66,7 @@ func (PdbAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			if pdb.Spec.Selector != nil && pdb.Spec.Selector.MatchLabels != nil {
 				for k, v := range pdb.Spec.Selector.MatchLabels {
 					failures = append(failures, common.Failure{
+						Severity:      common.SeverityMedium,
 						Text:          fmt.Sprintf("%s, expected pdb pod label %s=%s", pdb.Status.Conditions[0].Reason, k, v),
 						KubernetesDoc: doc,
 						Sensitive: []common.Sensitive{
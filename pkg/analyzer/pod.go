@@ -52,6 +52,7 @@ func (PodAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				if containerStatus.Type == v1.PodScheduled && containerStatus.Reason == "Unschedulable" {
 					if containerStatus.Message != "" {
 						failures = append(failures, common.Failure{
+							Severity:  common.SeverityHigh,
 							Text:      containerStatus.Message,
 							Sensitive: []common.Sensitive{},
 						})
@@ -107,6 +108,7 @@ func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerSt
 				}
 				if isEvtErrorReason(evt.Reason) && evt.Message != "" {
 					failures = append(failures, common.Failure{
+						Severity:  common.SeverityHigh,
 						Text:      evt.Message,
 						Sensitive: []common.Sensitive{},
 					})
@@ -114,11 +116,13 @@ func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerSt
 			} else if containerStatus.State.Waiting.Reason == "CrashLoopBackOff" && containerStatus.LastTerminationState.Terminated != nil {
 				// This represents container that is in CrashLoopBackOff state due to conditions such as OOMKilled
 				failures = append(failures, common.Failure{
+					Severity:  common.SeverityCritical,
 					Text:      fmt.Sprintf("the last termination reason is %s container=%s pod=%s", containerStatus.LastTerminationState.Terminated.Reason, containerStatus.Name, name),
 					Sensitive: []common.Sensitive{},
 				})
 			} else if isErrorReason(containerStatus.State.Waiting.Reason) && containerStatus.State.Waiting.Message != "" {
 				failures = append(failures, common.Failure{
+					Severity:  common.SeverityCritical,
 					Text:      containerStatus.State.Waiting.Message,
 					Sensitive: []common.Sensitive{},
 				})
@@ -133,6 +137,7 @@ func analyzeContainerStatusFailures(a common.Analyzer, statuses []v1.ContainerSt
 				}
 				if evt.Reason == "Unhealthy" && evt.Message != "" {
 					failures = append(failures, common.Failure{
+						Severity:  common.SeverityHigh,
 						Text:      evt.Message,
 						Sensitive: []common.Sensitive{},
 					})
@@ -41,6 +41,7 @@ func (analyzer PrivilegedContainerAnalyzer) Analyze(a common.Analyzer) ([]common
 			if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
 				doc := apiDoc.GetApiDocV2("spec.containers.securityContext.privileged")
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityCritical,
 					Text:          fmt.Sprintf("Container %s in Pod %s is running as a privileged container", container.Name, pod.Name),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
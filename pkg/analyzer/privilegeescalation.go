@@ -42,6 +42,7 @@ func (analyzer PrivilegeEscalationAnalyzer) Analyze(a common.Analyzer) ([]common
 				if *container.SecurityContext.AllowPrivilegeEscalation {
 					doc := apiDoc.GetApiDocV2("spec.containers[*].securityContext.allowPrivilegeEscalation")
 					failures = append(failures, common.Failure{
+						Severity: common.SeverityHigh,
 						Text: fmt.Sprintf("Container %s in Pod %s allows privilege escalation. Set `allowPrivilegeEscalation: false` in SecurityContext.",
 							container.Name, pod.Name),
 						KubernetesDoc: doc,
@@ -55,6 +56,7 @@ func (analyzer PrivilegeEscalationAnalyzer) Analyze(a common.Analyzer) ([]common
 			} else {
 				doc := apiDoc.GetApiDocV2("spec.containers[*].securityContext.allowPrivilegeEscalation")
 				failures = append(failures, common.Failure{
+					Severity: common.SeverityMedium,
 					Text: fmt.Sprintf("Container %s in Pod %s does not explicitly set `allowPrivilegeEscalation`. It should be set to `false`.",
 						container.Name, pod.Name),
 					KubernetesDoc: doc,
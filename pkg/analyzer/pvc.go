@@ -53,6 +53,7 @@ func (PvcAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			}
 			if evt.Reason == "ProvisioningFailed" && evt.Message != "" {
 				failures = append(failures, common.Failure{
+					Severity:  common.SeverityHigh,
 					Text:      evt.Message,
 					Sensitive: []common.Sensitive{},
 				})
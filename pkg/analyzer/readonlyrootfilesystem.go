@@ -40,6 +40,7 @@ func (analyzer ReadOnlyRootFilesystemAnalyzer) Analyze(a common.Analyzer) ([]com
 			if container.SecurityContext == nil || container.SecurityContext.ReadOnlyRootFilesystem == nil || !*container.SecurityContext.ReadOnlyRootFilesystem {
 				doc := apiDoc.GetApiDocV2("spec.containers.securityContext.readOnlyRootFilesystem")
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityLow,
 					Text:          fmt.Sprintf("Pod %s in namespace %s has container %s without read-only root filesystem enforced", pod.Name, pod.Namespace, container.Name),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -45,6 +45,7 @@ func (analyzer ResourceLimitsAnalyzer) Analyze(a common.Analyzer) ([]common.Resu
 			if missingCPU || missingMemory {
 				doc := apiDoc.GetApiDocV2("spec.containers[*].resources.limits")
 				failures = append(failures, common.Failure{
+					Severity: common.SeverityMedium,
 					Text: fmt.Sprintf("Container %s in Pod %s is missing resource limits (CPU: %v, Memory: %v)",
 						container.Name, pod.Name, missingCPU, missingMemory),
 					KubernetesDoc: doc,
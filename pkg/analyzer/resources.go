@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	podsResource                    = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	eventsResource                  = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+	servicesResource                = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	endpointsResource               = schema.GroupVersionResource{Version: "v1", Resource: "endpoints"}
+	secretsResource                 = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	configMapsResource              = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	nodesResource                   = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+	pvcsResource                    = schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+	replicationControllersResource  = schema.GroupVersionResource{Version: "v1", Resource: "replicationcontrollers"}
+	deploymentsResource             = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	replicaSetsResource             = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"}
+	statefulSetsResource            = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}
+	daemonSetsResource              = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"}
+	cronJobsResource                = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "cronjobs"}
+	ingressesResource               = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"}
+	ingressClassesResource          = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingressclasses"}
+	networkPoliciesResource         = schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"}
+	storageClassesResource          = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}
+	hpasResource                    = schema.GroupVersionResource{Group: "autoscaling", Version: "v2", Resource: "horizontalpodautoscalers"}
+	pdbsResource                    = schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"}
+	validatingWebhooksResource      = schema.GroupVersionResource{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "validatingwebhookconfigurations"}
+	mutatingWebhooksResource        = schema.GroupVersionResource{Group: "admissionregistration.k8s.io", Version: "v1", Resource: "mutatingwebhookconfigurations"}
+	gatewayClassesResource          = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gatewayclasses"}
+	gatewaysResource                = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+	httpRoutesResource              = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+	scaledObjectsResource           = schema.GroupVersionResource{Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects"}
+	policyReportsResource           = schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "policyreports"}
+	clusterPolicyReportsResource    = schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "clusterpolicyreports"}
+	podWorkloadResources            = []schema.GroupVersionResource{podsResource}
+	serviceWorkloadResources        = []schema.GroupVersionResource{servicesResource}
+	scaleTargetResources            = []schema.GroupVersionResource{deploymentsResource, replicationControllersResource, replicaSetsResource, statefulSetsResource}
+	webhookConfigurationResources   = []schema.GroupVersionResource{servicesResource, podsResource}
+	prometheusConfigurationResource = []schema.GroupVersionResource{podsResource, configMapsResource, secretsResource}
+)
+
+// parentResources are read by util.GetParent to resolve the owner chain of
+// any object an analyzer reports.
+var parentResources = []schema.GroupVersionResource{
+	replicaSetsResource,
+	deploymentsResource,
+	statefulSetsResource,
+	daemonSetsResource,
+	ingressesResource,
+	mutatingWebhooksResource,
+	validatingWebhooksResource,
+}
+
+// analyzerResources lists the API resources each analyzer reads, keyed by
+// the analyzer (filter) name. It is used to capture cluster snapshots that
+// can later be analyzed offline with `k8sgpt analyze --from-snapshot`.
+var analyzerResources = map[string][]schema.GroupVersionResource{
+	"Pod":                            {podsResource, eventsResource},
+	"Deployment":                     {deploymentsResource},
+	"ReplicaSet":                     {replicaSetsResource},
+	"PersistentVolumeClaim":          {pvcsResource, eventsResource},
+	"Service":                        {endpointsResource, servicesResource, eventsResource},
+	"Ingress":                        {ingressesResource, ingressClassesResource, servicesResource, secretsResource},
+	"StatefulSet":                    {statefulSetsResource, servicesResource, storageClassesResource, podsResource, eventsResource},
+	"CronJob":                        {cronJobsResource},
+	"Node":                           {nodesResource},
+	"ValidatingWebhookConfiguration": append([]schema.GroupVersionResource{validatingWebhooksResource}, webhookConfigurationResources...),
+	"MutatingWebhookConfiguration":   append([]schema.GroupVersionResource{mutatingWebhooksResource}, webhookConfigurationResources...),
+	"HostPath":                       podWorkloadResources,
+	"HostNetworking":                 podWorkloadResources,
+	"ServiceAccountToken":            podWorkloadResources,
+	"HTTPSOnlyServiceV2":             serviceWorkloadResources,
+	"ReadOnlyRootFilesystem":         podWorkloadResources,
+	"AllowedPortsService":            serviceWorkloadResources,
+	"RootUser":                       podWorkloadResources,
+	"PrivilegedContainer":            podWorkloadResources,
+	"NonRootUser":                    podWorkloadResources,
+	"LeastPrivilegedCapabilities":    podWorkloadResources,
+	"AppArmorProfile":                podWorkloadResources,
+	"HostNamespace":                  podWorkloadResources,
+	"PrivilegeEscalation":            podWorkloadResources,
+	"TrustedRegistry":                podWorkloadResources,
+	"ResourceLimits":                 podWorkloadResources,
+	"SecCompProfile":                 podWorkloadResources,
+	"RunAsUser":                      podWorkloadResources,
+	"DropCapabilities":               podWorkloadResources,
+	"HorizontalPodAutoScaler":        append([]schema.GroupVersionResource{hpasResource}, scaleTargetResources...),
+	"PodDisruptionBudget":            {pdbsResource},
+	"NetworkPolicy":                  {networkPoliciesResource, podsResource},
+	"Log":                            podWorkloadResources,
+	"GatewayClass":                   {gatewayClassesResource},
+	"Gateway":                        {gatewaysResource, gatewayClassesResource},
+	"HTTPRoute":                      {httpRoutesResource, gatewaysResource, servicesResource},
+	// Integrations
+	"ScaledObject":                  append([]schema.GroupVersionResource{scaledObjectsResource}, scaleTargetResources...),
+	"PolicyReport":                  {policyReportsResource},
+	"ClusterPolicyReport":           {clusterPolicyReportsResource},
+	"PrometheusConfigValidate":      prometheusConfigurationResource,
+	"PrometheusConfigRelabelReport": prometheusConfigurationResource,
+}
+
+// analyzersReadingLogs are the analyzers that tail container logs.
+var analyzersReadingLogs = []string{"Log"}
+
+// GetAnalyzerResources returns the sorted, de-duplicated API resources read by
+// the given analyzers, including the resources needed to resolve parent
+// objects. Analyzers without a known resource mapping are returned separately.
+func GetAnalyzerResources(analyzers []string) ([]schema.GroupVersionResource, []string) {
+	set := map[schema.GroupVersionResource]struct{}{}
+	for _, r := range parentResources {
+		set[r] = struct{}{}
+	}
+
+	var unknown []string
+	for _, name := range analyzers {
+		resources, ok := analyzerResources[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		for _, r := range resources {
+			set[r] = struct{}{}
+		}
+	}
+
+	resources := make([]schema.GroupVersionResource, 0, len(set))
+	for r := range set {
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].String() < resources[j].String()
+	})
+	return resources, unknown
+}
+
+// AnalyzersReadLogs reports whether any of the given analyzers tails container logs.
+func AnalyzersReadLogs(analyzers []string) bool {
+	for _, name := range analyzers {
+		for _, l := range analyzersReadingLogs {
+			if name == l {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LogTailLines returns the number of log lines the Log analyzer reads per container.
+func LogTailLines() int64 {
+	return tailLines
+}
+
+// GetResourceAnalyzers maps every resource read by the given analyzers to the
+// analyzers reading it. The resources only used to resolve parent objects are
+// left out, as they never change whether an object is failing. Analyzers
+// without a known resource mapping are returned separately.
+func GetResourceAnalyzers(analyzers []string) (map[schema.GroupVersionResource][]string, []string) {
+	resourceAnalyzers := map[schema.GroupVersionResource][]string{}
+	var unknown []string
+	for _, name := range analyzers {
+		resources, ok := analyzerResources[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		for _, r := range resources {
+			resourceAnalyzers[r] = append(resourceAnalyzers[r], name)
+		}
+	}
+	return resourceAnalyzers, unknown
+}
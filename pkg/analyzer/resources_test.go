@@ -0,0 +1,42 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzerResourcesAreDeclared(t *testing.T) {
+	for _, m := range []map[string]common.IAnalyzer{coreAnalyzerMap, additionalAnalyzerMap} {
+		for name := range m {
+			_, ok := analyzerResources[name]
+			require.Truef(t, ok, "analyzer %s does not declare the resources it reads", name)
+		}
+	}
+}
+
+func TestGetAnalyzerResources(t *testing.T) {
+	resources, unknown := GetAnalyzerResources([]string{"Pod", "RootUser", "Unknown"})
+	require.Equal(t, []string{"Unknown"}, unknown)
+	// Pods and events, plus the resources used to resolve parents.
+	require.Len(t, resources, len(parentResources)+2)
+	require.Contains(t, resources, podsResource)
+	require.Contains(t, resources, eventsResource)
+
+	require.True(t, AnalyzersReadLogs([]string{"Pod", "Log"}))
+	require.False(t, AnalyzersReadLogs([]string{"Pod"}))
+}
@@ -41,6 +41,7 @@ func (analyzer RootUserAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 			if container.SecurityContext == nil || (container.SecurityContext.RunAsUser != nil && *container.SecurityContext.RunAsUser == 0) {
 				doc := apiDoc.GetApiDocV2("spec.containers.securityContext.runAsUser")
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("Container %s in Pod %s is running as root user", container.Name, pod.Name),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -49,6 +49,7 @@ func (ReplicaSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			for _, rsStatus := range rs.Status.Conditions {
 				if rsStatus.Type == "ReplicaFailure" && rsStatus.Reason == "FailedCreate" {
 					failures = append(failures, common.Failure{
+						Severity:  common.SeverityHigh,
 						Text:      rsStatus.Message,
 						Sensitive: []common.Sensitive{},
 					})
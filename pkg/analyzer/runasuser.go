@@ -41,6 +41,7 @@ func (analyzer RunAsUserAnalyzer) Analyze(a common.Analyzer) ([]common.Result, e
 			if container.SecurityContext == nil || container.SecurityContext.RunAsUser == nil || *container.SecurityContext.RunAsUser < 1000000 {
 				doc := apiDoc.GetApiDocV2("spec.containers[*].securityContext.runAsUser")
 				failures = append(failures, common.Failure{
+					Severity: common.SeverityLow,
 					Text: fmt.Sprintf("Container %s in Pod %s does not explicitly set `runAsUser` to a UID greater than 1,000,000. This is required for security best practices.",
 						container.Name, pod.Name),
 					KubernetesDoc: doc,
@@ -41,6 +41,7 @@ func (analyzer SeccompProfileAnalyzer) Analyze(a common.Analyzer) ([]common.Resu
 			if container.SecurityContext == nil || container.SecurityContext.SeccompProfile == nil {
 				doc := apiDoc.GetApiDocV2("spec.containers[*].securityContext.seccompProfile")
 				failures = append(failures, common.Failure{
+					Severity: common.SeverityMedium,
 					Text: fmt.Sprintf("Container %s in Pod %s does not explicitly set `seccompProfile`. It should be defined to ensure security.",
 						container.Name, pod.Name),
 					KubernetesDoc: doc,
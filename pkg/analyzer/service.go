@@ -71,6 +71,7 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				doc := apiDoc.GetApiDocV2("spec.selector")
 
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("Service has no endpoints, expected label %s=%s", k, v),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -105,6 +106,7 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				doc := apiDoc.GetApiDocV2("subsets.notReadyAddresses")
 
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("Service has not ready endpoints, pods: %s, expected %d", pods, count),
 					KubernetesDoc: doc,
 					Sensitive:     []common.Sensitive{},
@@ -123,7 +125,8 @@ func (ServiceAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		for _, event := range events.Items {
 			if event.Type != "Normal" {
 				failures = append(failures, common.Failure{
-					Text: fmt.Sprintf("Service %s/%s has event %s", ep.Namespace, ep.Name, event.Message),
+					Severity: common.SeverityMedium,
+					Text:     fmt.Sprintf("Service %s/%s has event %s", ep.Namespace, ep.Name, event.Message),
 				})
 			}
 		}
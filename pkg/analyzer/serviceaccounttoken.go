@@ -41,6 +41,7 @@ func (analyzer ServiceAccountTokenAnalyzer) Analyze(a common.Analyzer) ([]common
 		if pod.Spec.AutomountServiceAccountToken != nil && *pod.Spec.AutomountServiceAccountToken {
 			doc := apiDoc.GetApiDocV2("spec.automountServiceAccountToken")
 			failures = append(failures, common.Failure{
+				Severity:      common.SeverityLow,
 				Text:          fmt.Sprintf("Pod %s has automountServiceAccountToken enabled, which may expose API credentials", pod.Name),
 				KubernetesDoc: doc,
 				Sensitive: []common.Sensitive{
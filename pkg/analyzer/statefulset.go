@@ -58,6 +58,7 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 			doc := apiDoc.GetApiDocV2("spec.serviceName")
 
 			failures = append(failures, common.Failure{
+				Severity: common.SeverityHigh,
 				Text: fmt.Sprintf(
 					"StatefulSet uses the service %s/%s which does not exist.",
 					sts.Namespace,
@@ -82,7 +83,8 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 					_, err := a.Client.GetClient().StorageV1().StorageClasses().Get(a.Context, *volumeClaimTemplate.Spec.StorageClassName, metav1.GetOptions{})
 					if err != nil {
 						failures = append(failures, common.Failure{
-							Text: fmt.Sprintf("StatefulSet uses the storage class %s which does not exist.", *volumeClaimTemplate.Spec.StorageClassName),
+							Severity: common.SeverityHigh,
+							Text:     fmt.Sprintf("StatefulSet uses the storage class %s which does not exist.", *volumeClaimTemplate.Spec.StorageClassName),
 							Sensitive: []common.Sensitive{
 								{
 									Unmasked: *volumeClaimTemplate.Spec.StorageClassName,
@@ -105,6 +107,7 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 							break
 						}
 						failures = append(failures, common.Failure{
+							Severity:  common.SeverityHigh,
 							Text:      evt.Message,
 							Sensitive: []common.Sensitive{},
 						})
@@ -113,7 +116,8 @@ func (StatefulSetAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				}
 				if pod.Status.Phase != "Running" {
 					failures = append(failures, common.Failure{
-						Text: fmt.Sprintf("Statefulset pod %s in the namespace %s is not in running state.", pod.Name, pod.Namespace),
+						Severity: common.SeverityHigh,
+						Text:     fmt.Sprintf("Statefulset pod %s in the namespace %s is not in running state.", pod.Name, pod.Namespace),
 						Sensitive: []common.Sensitive{
 							{
 								Unmasked: sts.Namespace,
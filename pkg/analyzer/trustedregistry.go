@@ -69,6 +69,7 @@ func (analyzer TrustedRegistryAnalyzer) Analyze(a common.Analyzer) ([]common.Res
 			if !isTrusted {
 				doc := apiDoc.GetApiDocV2("spec.containers[*].image")
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("Container %s in Pod %s is using an untrusted image registry: %s", container.Name, pod.Name, registry),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -60,6 +60,7 @@ func (ValidatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 			if err != nil {
 				// If the service is not found, we can't check the pods
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("Service %s not found as mapped to by Validating Webhook %s", svc.Name, webhook.Name),
 					KubernetesDoc: apiDoc.GetApiDocV2("spec.webhook.clientConfig.service"),
 					Sensitive: []common.Sensitive{
@@ -95,6 +96,7 @@ func (ValidatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 
 			if len(pods.Items) == 0 {
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          fmt.Sprintf("No active pods found within service %s as mapped to by Validating Webhook %s", svc.Name, webhook.Name),
 					KubernetesDoc: apiDoc.GetApiDocV2("spec.webhook.clientConfig.service"),
 					Sensitive: []common.Sensitive{
@@ -110,6 +112,7 @@ func (ValidatingWebhookAnalyzer) Analyze(a common.Analyzer) ([]common.Result, er
 				if pod.Status.Phase != "Running" {
 					doc := apiDoc.GetApiDocV2("spec.webhook")
 					failures = append(failures, common.Failure{
+						Severity: common.SeverityHigh,
 						Text: fmt.Sprintf(
 							"Validating Webhook (%s) is pointing to an inactive receiver pod (%s)",
 							webhook.Name,
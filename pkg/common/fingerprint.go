@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+var (
+	uidPattern    = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+	numberPattern = regexp.MustCompile(`[0-9]+`)
+)
+
+// SetFingerprints sets a stable fingerprint on the result and each of its
+// failures, so the same problem can be recognised across analysis runs.
+//
+// Objects owned by a parent are identified by their parent, so a problem
+// survives pods being replaced. Failure texts are normalised by removing the
+// object name, UIDs and numbers, which change between runs without the
+// problem itself changing. Results of different clusters never share a
+// fingerprint.
+func (r *Result) SetFingerprints() {
+	identity := r.identity()
+	r.Fingerprint = fingerprint(r.Kind, identity)
+	for i := range r.Error {
+		r.Error[i].Fingerprint = fingerprint(r.Kind, identity, r.normalize(r.Error[i].Text))
+	}
+}
+
+func (r *Result) identity() string {
+	identity := r.Name
+	if r.ParentObject != "" {
+		identity = r.ParentObject
+		if namespace, _, found := strings.Cut(r.Name, "/"); found {
+			identity = namespace + "/" + r.ParentObject
+		}
+	}
+	if r.Cluster != "" {
+		identity = r.Cluster + "/" + identity
+	}
+	return identity
+}
+
+func (r *Result) normalize(text string) string {
+	name := r.Name
+	if _, n, found := strings.Cut(r.Name, "/"); found {
+		name = n
+	}
+	if name != "" {
+		text = strings.ReplaceAll(text, name, "<name>")
+	}
+	text = uidPattern.ReplaceAllString(text, "<uid>")
+	return numberPattern.ReplaceAllString(text, "0")
+}
+
+func fingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
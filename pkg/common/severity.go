@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity ranks how urgent a failure is.
+type Severity string
+
+const (
+	// SeverityCritical is for workloads or nodes that are down.
+	SeverityCritical Severity = "critical"
+	// SeverityHigh is for broken resources or serious security risks.
+	SeverityHigh Severity = "high"
+	// SeverityMedium is for degraded resources and hardening gaps.
+	SeverityMedium Severity = "medium"
+	// SeverityLow is for problems unlikely to cause an outage.
+	SeverityLow Severity = "low"
+	// SeverityInfo is for noteworthy but intended configurations.
+	SeverityInfo Severity = "info"
+)
+
+// Severities lists the severities from the most to the least severe.
+var Severities = []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityInfo}
+
+// ParseSeverity parses a severity name, case insensitive.
+func ParseSeverity(s string) (Severity, error) {
+	for _, severity := range Severities {
+		if strings.EqualFold(s, string(severity)) {
+			return severity, nil
+		}
+	}
+	names := make([]string, 0, len(Severities))
+	for _, severity := range Severities {
+		names = append(names, string(severity))
+	}
+	return "", fmt.Errorf("invalid severity %q, must be one of %s", s, strings.Join(names, ", "))
+}
+
+func (s Severity) rank() int {
+	for i, severity := range Severities {
+		if s == severity {
+			return len(Severities) - i
+		}
+	}
+	// Unknown severities, e.g. from custom analyzers, rank as medium.
+	return SeverityMedium.rank()
+}
+
+// AtLeast reports whether s is as severe as or more severe than other.
+func (s Severity) AtLeast(other Severity) bool {
+	return s.rank() >= other.rank()
+}
+
+// GetSeverity returns the severity of the failure. Failures without a
+// severity, e.g. from custom analyzers, are considered medium.
+func (f Failure) GetSeverity() Severity {
+	if f.Severity == "" {
+		return SeverityMedium
+	}
+	return f.Severity
+}
+
+// HighestSeverity returns the highest severity of the result's failures.
+func (r Result) HighestSeverity() Severity {
+	highest := SeverityInfo
+	for _, failure := range r.Error {
+		if severity := failure.GetSeverity(); severity.AtLeast(highest) {
+			highest = severity
+		}
+	}
+	return highest
+}
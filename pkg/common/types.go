@@ -71,11 +71,47 @@ type PreAnalysis struct {
 }
 
 type Result struct {
-	Kind         string    `json:"kind"`
-	Name         string    `json:"name"`
-	Error        []Failure `json:"error"`
-	Details      string    `json:"details"`
-	ParentObject string    `json:"parentObject"`
+	Kind         string       `json:"kind"`
+	Name         string       `json:"name"`
+	Error        []Failure    `json:"error"`
+	Details      string       `json:"details"`
+	ParentObject string       `json:"parentObject"`
+	Fingerprint  string       `json:"fingerprint,omitempty"`
+	Analyzer     string       `json:"analyzer,omitempty"`
+	Cluster      string       `json:"cluster,omitempty"`
+	Provider     string       `json:"provider,omitempty"`
+	Explanation  *Explanation `json:"explanation,omitempty"`
+	Remediation  *Remediation `json:"remediation,omitempty"`
+}
+
+// Explanation is the structured form of the details of a result.
+type Explanation struct {
+	Summary   string   `json:"summary"`
+	RootCause string   `json:"rootCause,omitempty"`
+	Steps     []string `json:"steps,omitempty"`
+	Commands  []string `json:"commands,omitempty"`
+	// Confidence is low, medium or high, or empty if the backend did not
+	// give a structured explanation.
+	Confidence string `json:"confidence,omitempty"`
+}
+
+// Remediation is a patch proposed to fix the object of a result.
+type Remediation struct {
+	// Object is the patched object, as Kind/name. The pods of a workload are
+	// fixed by patching the workload.
+	Object string `json:"object,omitempty"`
+	// Type is strategic, merge or json, or none if no patch was proposed.
+	Type        string `json:"type"`
+	Patch       string `json:"patch,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Diff is the change to the object, as validated by a server-side
+	// dry-run. Error tells why the patch is not valid otherwise.
+	Diff    string `json:"diff,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Applied bool   `json:"applied,omitempty"`
+	// ResourceVersion is the version of the object validated by the
+	// dry-run. The patch is applied only to this version.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
 }
 
 type AnalysisStats struct {
@@ -87,6 +123,8 @@ type Failure struct {
 	Text          string
 	KubernetesDoc string
 	Sensitive     []Sensitive
+	Severity      Severity `json:",omitempty"`
+	Fingerprint   string   `json:",omitempty"`
 }
 
 type Sensitive struct {
@@ -64,6 +64,7 @@ func (e *EKSAnalyzer) Analyze(analysis common.Analyzer) ([]common.Result, error)
 			for _, issue := range result.Cluster.Health.Issues {
 				err := make([]common.Failure, 0)
 				err = append(err, common.Failure{
+					Severity:      common.SeverityHigh,
 					Text:          issue.String(),
 					KubernetesDoc: "",
 					Sensitive:     nil,
@@ -65,6 +65,7 @@ func (s *ScaledObjectAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 			}
 		default:
 			failures = append(failures, common.Failure{
+				Severity:  common.SeverityHigh,
 				Text:      fmt.Sprintf("ScaledObject uses %s as ScaleTargetRef which is not an option.", scaleTargetRef.Kind),
 				Sensitive: []common.Sensitive{},
 			})
@@ -74,6 +75,7 @@ func (s *ScaledObjectAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 			doc := apiDoc.GetApiDocV2("spec.scaleTargetRef")
 
 			failures = append(failures, common.Failure{
+				Severity:      common.SeverityHigh,
 				Text:          fmt.Sprintf("ScaledObject uses %s/%s as ScaleTargetRef which does not exist.", scaleTargetRef.Kind, scaleTargetRef.Name),
 				KubernetesDoc: doc,
 				Sensitive: []common.Sensitive{
@@ -100,6 +102,7 @@ func (s *ScaledObjectAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 				doc := apiDoc.GetApiDocV2("spec.scaleTargetRef.kind")
 
 				failures = append(failures, common.Failure{
+					Severity:      common.SeverityMedium,
 					Text:          fmt.Sprintf("%s %s/%s does not have resource configured.", scaleTargetRef.Kind, so.Namespace, scaleTargetRef.Name),
 					KubernetesDoc: doc,
 					Sensitive: []common.Sensitive{
@@ -118,7 +121,8 @@ func (s *ScaledObjectAnalyzer) Analyze(a common.Analyzer) ([]common.Result, erro
 
 			if evt.Type != "Normal" {
 				failures = append(failures, common.Failure{
-					Text: evt.Message,
+					Severity: common.SeverityMedium,
+					Text:     evt.Message,
 					Sensitive: []common.Sensitive{
 						{
 							Unmasked: scaleTargetRef.Name,
@@ -55,6 +55,7 @@ func (KyvernoAnalyzer) analyzePolicyReports(a common.Analyzer) ([]common.Result,
 				// get the vulnerability ID
 				// get the vulnerability description
 				failures = append(failures, common.Failure{
+					Severity:  policySeverity(vuln.Severity),
 					Text:      fmt.Sprintf("policy failure: %s (message: %s)", vuln.Policy, vuln.Message),
 					Sensitive: []common.Sensitive{},
 				})
@@ -109,6 +110,7 @@ func (t KyvernoAnalyzer) analyzeClusterPolicyReports(a common.Analyzer) ([]commo
 				// get the vulnerability ID
 				// get the vulnerability description
 				failures = append(failures, common.Failure{
+					Severity:  common.SeverityCritical,
 					Text:      fmt.Sprintf("critical Vulnerability found ID: %s (learn more at: %s)", vuln.ID, vuln.Source),
 					Sensitive: []common.Sensitive{},
 				})
@@ -160,3 +162,12 @@ func (t KyvernoAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 	}
 	return make([]common.Result, 0), nil
 }
+
+// policySeverity maps the severity of a policy to the severity of a failure,
+// policies without a severity are considered medium.
+func policySeverity(severity v1alpha2.PolicySeverity) common.Severity {
+	if s, err := common.ParseSeverity(string(severity)); err == nil {
+		return s
+	}
+	return common.SeverityMedium
+}
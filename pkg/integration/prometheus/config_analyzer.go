@@ -64,20 +64,23 @@ func (c *ConfigAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 		config, err := unmarshalPromConfigBytes(pc.b)
 		if err != nil {
 			failures = append(failures, common.Failure{
-				Text: fmt.Sprintf("error validating Prometheus YAML configuration: %s", err),
+				Severity: common.SeverityHigh,
+				Text:     fmt.Sprintf("error validating Prometheus YAML configuration: %s", err),
 			})
 		}
 		_, err = yaml.Marshal(config)
 		if err != nil {
 			failures = append(failures, common.Failure{
-				Text: fmt.Sprintf("error validating Prometheus struct configuration: %s", err),
+				Severity: common.SeverityHigh,
+				Text:     fmt.Sprintf("error validating Prometheus struct configuration: %s", err),
 			})
 		}
 
 		// Check for empty scrape config.
 		if len(config.ScrapeConfigs) == 0 {
 			failures = append(failures, common.Failure{
-				Text: "no scrape configurations. Prometheus will not scrape any metrics.",
+				Severity: common.SeverityMedium,
+				Text:     "no scrape configurations. Prometheus will not scrape any metrics.",
 			})
 		}
 
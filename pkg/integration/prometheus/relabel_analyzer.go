@@ -57,7 +57,8 @@ func (r *RelabelAnalyzer) Analyze(a common.Analyzer) ([]common.Result, error) {
 				continue
 			}
 			failures = append(failures, common.Failure{
-				Text: fmt.Sprintf("job_name:\n%s\nrelabel_configs:\n%s\nkubernetes_sd_configs:\n%s\n", sc.JobName, string(brc), string(bsd)),
+				Severity: common.SeverityInfo,
+				Text:     fmt.Sprintf("job_name:\n%s\nrelabel_configs:\n%s\nkubernetes_sd_configs:\n%s\n", sc.JobName, string(brc), string(bsd)),
 			})
 			i++
 		}
@@ -14,6 +14,11 @@ limitations under the License.
 package kubernetes
 
 import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	"k8s.io/client-go/rest"
@@ -33,6 +38,19 @@ func (c *Client) GetCtrlClient() ctrl.Client {
 	return c.CtrlClient
 }
 
+// SecretValue returns the value of a key of a Secret.
+func (c *Client) SecretValue(ctx context.Context, namespace, name, key string) (string, error) {
+	secret, err := c.Client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s/%s has no key %s", namespace, name, key)
+	}
+	return string(value), nil
+}
+
 func NewClient(kubecontext string, kubeconfig string) (*Client, error) {
 	var config *rest.Config
 	config, err := rest.InClusterConfig()
@@ -76,3 +94,22 @@ func NewClient(kubecontext string, kubeconfig string) (*Client, error) {
 		ServerVersion: serverVersion,
 	}, nil
 }
+
+// ListContexts returns the names of all contexts in the kubeconfig, sorted.
+func ListContexts(kubeconfig string) ([]string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		loadingRules.ExplicitPath = kubeconfig
+	}
+	config, err := loadingRules.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts, nil
+}
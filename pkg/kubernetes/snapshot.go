@@ -0,0 +1,391 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	kyverno "github.com/kyverno/policy-reporter-kyverno-plugin/pkg/crd/api/policyreport/v1alpha2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	fakerest "k8s.io/client-go/rest/fake"
+	ktesting "k8s.io/client-go/testing"
+	fakectrl "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gtwapi "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// SnapshotManifestFile is the archive entry describing a captured snapshot.
+	SnapshotManifestFile = "manifest.json"
+	// SnapshotResourcesDir holds the captured manifests inside a snapshot archive.
+	SnapshotResourcesDir = "resources"
+	// SnapshotLogsDir holds the captured container logs inside a snapshot archive.
+	SnapshotLogsDir = "logs"
+)
+
+var snapshotExtensions = []string{".yaml", ".yml", ".json"}
+
+// SnapshotLogPath returns the archive entry holding the logs of a container.
+func SnapshotLogPath(namespace, pod, container string) string {
+	return path.Join(SnapshotLogsDir, namespace, pod, container+".log")
+}
+
+// NewClientFromSnapshot returns a Client whose clientsets are served from the
+// manifests found at path instead of a live API server. path may be a
+// directory, a single manifest file, or a .tar/.tar.gz/.tgz archive of
+// manifests (e.g. a `kubectl get -A -o yaml` dump or a must-gather).
+func NewClientFromSnapshot(path string) (*Client, error) {
+	snapshotScheme, err := newSnapshotScheme()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := loadSnapshot(snapshotScheme, path)
+	if err != nil {
+		return nil, err
+	}
+
+	clientSet := fake.NewSimpleClientset(snapshot.objects...)
+	// The fake clientset ignores field selectors, but util.FetchLatestEvent
+	// relies on them to find the events of a single object.
+	clientSet.PrependReactor("list", "events", eventFieldSelectorReactor(clientSet.Tracker()))
+
+	ctrlClient := fakectrl.NewClientBuilder().
+		WithScheme(snapshotScheme).
+		WithRuntimeObjects(snapshot.objects...).
+		Build()
+
+	serverVersion, err := clientSet.Discovery().ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		Client:        &snapshotClientset{Clientset: clientSet, logs: snapshot.logs},
+		CtrlClient:    ctrlClient,
+		Config:        &rest.Config{},
+		ServerVersion: serverVersion,
+	}, nil
+}
+
+// LoadSnapshotObjects decodes every Kubernetes object found at path. Lists are
+// flattened, and documents of a kind unknown to the client scheme are skipped.
+func LoadSnapshotObjects(path string) ([]runtime.Object, error) {
+	snapshotScheme, err := newSnapshotScheme()
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := loadSnapshot(snapshotScheme, path)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.objects, nil
+}
+
+type snapshotContent struct {
+	scheme  *runtime.Scheme
+	objects []runtime.Object
+	// logs maps "namespace/pod/container" to the captured container logs.
+	logs map[string]string
+}
+
+// add reads a single snapshot entry. name is the slash separated path of the
+// entry relative to the snapshot root.
+func (c *snapshotContent) add(name string, r io.Reader) error {
+	if strings.HasPrefix(name, SnapshotLogsDir+"/") && strings.HasSuffix(name, ".log") {
+		parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(name, SnapshotLogsDir+"/"), ".log"), "/")
+		if len(parts) != 3 {
+			return nil
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+		c.logs[strings.Join(parts, "/")] = string(data)
+		return nil
+	}
+
+	if !isManifestFile(name) {
+		return nil
+	}
+	decoded, err := decodeManifests(c.scheme, r)
+	if err != nil {
+		return fmt.Errorf("decoding %s: %w", name, err)
+	}
+	c.objects = append(c.objects, decoded...)
+	return nil
+}
+
+func loadSnapshot(snapshotScheme *runtime.Scheme, snapshotPath string) (*snapshotContent, error) {
+	info, err := os.Stat(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+
+	content := &snapshotContent{
+		scheme: snapshotScheme,
+		logs:   map[string]string{},
+	}
+
+	switch {
+	case info.IsDir():
+		err = filepath.WalkDir(snapshotPath, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(snapshotPath, p)
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return content.add(filepath.ToSlash(rel), f)
+		})
+	case isTarArchive(snapshotPath):
+		err = walkTarArchive(snapshotPath, func(name string, r io.Reader) error {
+			return content.add(path.Clean(strings.TrimPrefix(name, "./")), r)
+		})
+	default:
+		var f *os.File
+		f, err = os.Open(snapshotPath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		err = content.add(filepath.Base(snapshotPath), f)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+func newSnapshotScheme() (*runtime.Scheme, error) {
+	s := runtime.NewScheme()
+	if err := scheme.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	if err := gtwapi.Install(s); err != nil {
+		return nil, err
+	}
+	if err := kyverno.AddToScheme(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func isManifestFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range snapshotExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func isTarArchive(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".tar") || strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
+
+// walkTarArchive calls fn for every regular file in the (optionally gzipped)
+// tar archive at path.
+func walkTarArchive(path string, fn func(name string, r io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if !strings.HasSuffix(strings.ToLower(path), ".tar") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func decodeManifests(s *runtime.Scheme, r io.Reader) ([]runtime.Object, error) {
+	var objects []runtime.Object
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		u := &unstructured.Unstructured{}
+		if err := decoder.Decode(&u.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		// Skip empty documents and files that are not Kubernetes objects.
+		if u.GetKind() == "" {
+			continue
+		}
+
+		if u.IsList() {
+			list, err := u.ToList()
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				if obj, ok := toTyped(s, &list.Items[i]); ok {
+					objects = append(objects, obj)
+				}
+			}
+			continue
+		}
+
+		if obj, ok := toTyped(s, u); ok {
+			objects = append(objects, obj)
+		}
+	}
+}
+
+func toTyped(s *runtime.Scheme, u *unstructured.Unstructured) (runtime.Object, bool) {
+	obj, err := s.New(u.GroupVersionKind())
+	if err != nil {
+		return nil, false
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+func eventFieldSelectorReactor(tracker ktesting.ObjectTracker) ktesting.ReactionFunc {
+	eventsResource := schema.GroupVersionResource{Version: "v1", Resource: "events"}
+	eventsKind := schema.GroupVersionKind{Version: "v1", Kind: "Event"}
+
+	return func(action ktesting.Action) (bool, runtime.Object, error) {
+		listAction, ok := action.(ktesting.ListAction)
+		if !ok || action.GetResource() != eventsResource {
+			return false, nil, nil
+		}
+		selector := listAction.GetListRestrictions().Fields
+		if selector == nil || selector.Empty() {
+			return false, nil, nil
+		}
+
+		obj, err := tracker.List(eventsResource, eventsKind, action.GetNamespace())
+		if err != nil {
+			return true, nil, err
+		}
+		list := obj.(*v1.EventList)
+
+		filtered := &v1.EventList{ListMeta: list.ListMeta}
+		for _, event := range list.Items {
+			if selector.Matches(eventFields(&event)) {
+				filtered.Items = append(filtered.Items, event)
+			}
+		}
+		return true, filtered, nil
+	}
+}
+
+func eventFields(event *v1.Event) fields.Set {
+	return fields.Set{
+		"involvedObject.kind":      event.InvolvedObject.Kind,
+		"involvedObject.name":      event.InvolvedObject.Name,
+		"involvedObject.namespace": event.InvolvedObject.Namespace,
+		"involvedObject.uid":       string(event.InvolvedObject.UID),
+		"reason":                   event.Reason,
+		"type":                     event.Type,
+	}
+}
+
+// snapshotClientset serves container logs from the snapshot; the embedded
+// fake clientset would otherwise answer every log request with "fake logs".
+type snapshotClientset struct {
+	*fake.Clientset
+	logs map[string]string
+}
+
+func (c *snapshotClientset) CoreV1() corev1.CoreV1Interface {
+	return &snapshotCoreV1{CoreV1Interface: c.Clientset.CoreV1(), logs: c.logs}
+}
+
+type snapshotCoreV1 struct {
+	corev1.CoreV1Interface
+	logs map[string]string
+}
+
+func (c *snapshotCoreV1) Pods(namespace string) corev1.PodInterface {
+	return &snapshotPods{PodInterface: c.CoreV1Interface.Pods(namespace), namespace: namespace, logs: c.logs}
+}
+
+type snapshotPods struct {
+	corev1.PodInterface
+	namespace string
+	logs      map[string]string
+}
+
+func (p *snapshotPods) GetLogs(name string, opts *v1.PodLogOptions) *rest.Request {
+	logs := p.logs[strings.Join([]string{p.namespace, name, opts.Container}, "/")]
+	fakeClient := &fakerest.RESTClient{
+		Client: fakerest.CreateHTTPClient(func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(logs)),
+			}, nil
+		}),
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		GroupVersion:         v1.SchemeGroupVersion,
+		VersionedAPIPath:     fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/log", p.namespace, name),
+	}
+	return fakeClient.Request()
+}
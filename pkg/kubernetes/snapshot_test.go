@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const snapshotList = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: example
+    namespace: default
+  status:
+    phase: Pending
+- apiVersion: v1
+  kind: Event
+  metadata:
+    name: example.1
+    namespace: default
+  involvedObject:
+    kind: Pod
+    name: example
+  reason: FailedMount
+- apiVersion: v1
+  kind: Event
+  metadata:
+    name: other.1
+    namespace: default
+  involvedObject:
+    kind: Pod
+    name: other
+  reason: Unhealthy
+`
+
+const snapshotDeployment = `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "example", "namespace": "default"}}`
+
+const snapshotUnknown = `---
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: skipped
+---
+`
+
+func writeSnapshotDir(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "list.yaml"), []byte(snapshotList), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "apps"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "apps", "deployment.json"), []byte(snapshotDeployment), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unknown.yml"), []byte(snapshotUnknown), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0600))
+	return dir
+}
+
+func writeSnapshotArchive(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "snapshot.tar.gz")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range map[string]string{
+		"resources/list.yaml":       snapshotList,
+		"resources/deployment.json": snapshotDeployment,
+		"resources/unknown.yml":     snapshotUnknown,
+	} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return path
+}
+
+func TestLoadSnapshotObjects(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{
+			name: "directory",
+			path: writeSnapshotDir(t),
+		},
+		{
+			name: "archive",
+			path: writeSnapshotArchive(t),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			objects, err := LoadSnapshotObjects(tt.path)
+			require.NoError(t, err)
+			// Pod, two events and the deployment; the unknown kind is skipped.
+			require.Len(t, objects, 4)
+		})
+	}
+
+	_, err := LoadSnapshotObjects(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorContains(t, err, "reading snapshot")
+}
+
+func TestNewClientFromSnapshot(t *testing.T) {
+	client, err := NewClientFromSnapshot(writeSnapshotDir(t))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	pods, err := client.GetClient().CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, pods.Items, 1)
+
+	deployments, err := client.GetClient().AppsV1().Deployments("").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, deployments.Items, 1)
+
+	events, err := client.GetClient().CoreV1().Events("default").List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.name=example",
+	})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	require.Equal(t, "FailedMount", events.Items[0].Reason)
+}
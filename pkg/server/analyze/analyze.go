@@ -33,6 +33,9 @@ func (h *Handler) Analyze(ctx context.Context, i *schemav1.AnalyzeRequest) (
 		false,      // Interactive mode disabled in server mode
 		[]string{}, //TODO: add custom http headers in server mode
 		false,      // with stats disable
+		"",         // analyze the live cluster, snapshots are not supported in server mode
+		nil,        // analyze the cluster of the server only
+		"",         // suppressions_file from the config, or .k8sgptignore
 	)
 	if err != nil {
 		return &schemav1.AnalyzeResponse{}, err
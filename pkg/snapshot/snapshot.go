@@ -0,0 +1,265 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/analyzer"
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	k "k8s.io/client-go/kubernetes"
+)
+
+// FormatVersion is the version of the snapshot archive layout. It is bumped
+// whenever the layout changes in a way older readers cannot handle.
+const FormatVersion = "v1"
+
+// Options controls what is captured into a snapshot.
+type Options struct {
+	// Namespace limits namespaced resources to a single namespace. Empty
+	// captures all namespaces.
+	Namespace string
+	// Analyzers are the analyzer (filter) names whose inputs are captured.
+	Analyzers []string
+	// TailLines is the number of log lines captured per container.
+	TailLines int64
+	// K8sGPTVersion is recorded in the manifest.
+	K8sGPTVersion string
+}
+
+// Manifest describes the content of a snapshot archive.
+type Manifest struct {
+	FormatVersion string          `json:"formatVersion"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	K8sGPTVersion string          `json:"k8sgptVersion,omitempty"`
+	ServerVersion *version.Info   `json:"serverVersion,omitempty"`
+	Namespace     string          `json:"namespace,omitempty"`
+	Analyzers     []string        `json:"analyzers"`
+	Resources     []ResourceEntry `json:"resources"`
+	Logs          []LogEntry      `json:"logs,omitempty"`
+	Warnings      []string        `json:"warnings,omitempty"`
+}
+
+type ResourceEntry struct {
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+	File     string `json:"file"`
+	Count    int    `json:"count"`
+}
+
+type LogEntry struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	File      string `json:"file"`
+	TailLines int64  `json:"tailLines"`
+}
+
+// Capture writes a gzipped tar archive to w containing every resource, event
+// and container log read by the analyzers in opts, plus a manifest. The
+// archive can be analyzed offline with `k8sgpt analyze --from-snapshot`.
+func Capture(ctx context.Context, client *kubernetes.Client, opts Options, w io.Writer) (*Manifest, error) {
+	dynamicClient, err := dynamic.NewForConfig(client.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+	return capture(ctx, client.GetClient(), dynamicClient, opts, w)
+}
+
+func capture(ctx context.Context, client k.Interface, dynamicClient dynamic.Interface, opts Options, w io.Writer) (*Manifest, error) {
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		K8sGPTVersion: opts.K8sGPTVersion,
+		Namespace:     opts.Namespace,
+		Analyzers:     opts.Analyzers,
+	}
+
+	serverVersion, err := client.Discovery().ServerVersion()
+	if err != nil {
+		manifest.Warnings = append(manifest.Warnings, fmt.Sprintf("could not read server version: %s", err))
+	}
+	manifest.ServerVersion = serverVersion
+
+	resources, unknown := analyzer.GetAnalyzerResources(opts.Analyzers)
+	for _, name := range unknown {
+		manifest.Warnings = append(manifest.Warnings, fmt.Sprintf("analyzer %s does not declare the resources it reads; its inputs are not captured", name))
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	var pods []v1.Pod
+	for _, gvr := range resources {
+		apiResource, err := findAPIResource(client.Discovery(), gvr)
+		if err != nil {
+			manifest.Warnings = append(manifest.Warnings, fmt.Sprintf("skipping %s: %s", gvr, err))
+			continue
+		}
+
+		var list *unstructured.UnstructuredList
+		if apiResource.Namespaced {
+			list, err = dynamicClient.Resource(gvr).Namespace(opts.Namespace).List(ctx, metav1.ListOptions{})
+		} else {
+			list, err = dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{})
+		}
+		if err != nil {
+			manifest.Warnings = append(manifest.Warnings, fmt.Sprintf("listing %s: %s", gvr, err))
+			continue
+		}
+
+		for i := range list.Items {
+			item := &list.Items[i]
+			item.SetAPIVersion(gvr.GroupVersion().String())
+			item.SetKind(apiResource.Kind)
+			item.SetManagedFields(nil)
+			if gvr.Group == "" && gvr.Resource == "secrets" {
+				redactSecret(item)
+			}
+			if gvr.Group == "" && gvr.Resource == "pods" {
+				var pod v1.Pod
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &pod); err == nil {
+					pods = append(pods, pod)
+				}
+			}
+		}
+
+		file := resourceFile(gvr)
+		if err := writeJSON(tw, file, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      list.Items,
+		}); err != nil {
+			return nil, err
+		}
+		manifest.Resources = append(manifest.Resources, ResourceEntry{
+			Group:    gvr.Group,
+			Version:  gvr.Version,
+			Resource: gvr.Resource,
+			File:     file,
+			Count:    len(list.Items),
+		})
+	}
+
+	if analyzer.AnalyzersReadLogs(opts.Analyzers) {
+		for _, pod := range pods {
+			for _, c := range pod.Spec.Containers {
+				tailLines := opts.TailLines
+				logs, err := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
+					Container: c.Name,
+					TailLines: &tailLines,
+				}).DoRaw(ctx)
+				if err != nil {
+					manifest.Warnings = append(manifest.Warnings, fmt.Sprintf("reading logs of %s/%s container %s: %s", pod.Namespace, pod.Name, c.Name, err))
+					continue
+				}
+				file := kubernetes.SnapshotLogPath(pod.Namespace, pod.Name, c.Name)
+				if err := writeFile(tw, file, logs); err != nil {
+					return nil, err
+				}
+				manifest.Logs = append(manifest.Logs, LogEntry{
+					Namespace: pod.Namespace,
+					Pod:       pod.Name,
+					Container: c.Name,
+					File:      file,
+					TailLines: tailLines,
+				})
+			}
+		}
+	}
+
+	if err := writeJSON(tw, kubernetes.SnapshotManifestFile, manifest); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func findAPIResource(client discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (*metav1.APIResource, error) {
+	resourceList, err := client.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		return nil, err
+	}
+	for i := range resourceList.APIResources {
+		if resourceList.APIResources[i].Name == gvr.Resource {
+			return &resourceList.APIResources[i], nil
+		}
+	}
+	return nil, fmt.Errorf("resource not served by the API server")
+}
+
+func resourceFile(gvr schema.GroupVersionResource) string {
+	group := gvr.Group
+	if group == "" {
+		group = "core"
+	}
+	return path.Join(kubernetes.SnapshotResourcesDir, group, gvr.Version, gvr.Resource+".json")
+}
+
+// redactSecret drops the secret payload but keeps the keys, so analyzers that
+// only check for the existence of a secret behave the same offline.
+func redactSecret(item *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(item.Object, "stringData")
+	data, found, err := unstructured.NestedMap(item.Object, "data")
+	if err != nil || !found {
+		return
+	}
+	for key := range data {
+		data[key] = ""
+	}
+	_ = unstructured.SetNestedMap(item.Object, data, "data")
+}
+
+func writeJSON(tw *tar.Writer, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling %s: %w", name, err)
+	}
+	return writeFile(tw, name, data)
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:     name,
+		Mode:     0600,
+		Size:     int64(len(data)),
+		Typeflag: tar.TypeReg,
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The K8sGPT Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k8sgpt-ai/k8sgpt/pkg/kubernetes"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+func TestCaptureRoundTrip(t *testing.T) {
+	objects := []runtime.Object{
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "app", Image: "nginx"}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodPending},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kube-system"},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "default"},
+			Data:       map[string][]byte{"tls.key": []byte("private")},
+		},
+	}
+
+	client := fake.NewSimpleClientset(objects...)
+	client.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Namespaced: true, Kind: "Pod"},
+				{Name: "events", Namespaced: true, Kind: "Event"},
+				{Name: "secrets", Namespaced: true, Kind: "Secret"},
+			},
+		},
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme.Scheme,
+		map[schema.GroupVersionResource]string{
+			{Version: "v1", Resource: "pods"}:    "PodList",
+			{Version: "v1", Resource: "events"}:  "EventList",
+			{Version: "v1", Resource: "secrets"}: "SecretList",
+		}, objects...)
+
+	path := filepath.Join(t.TempDir(), "snapshot.tar.gz")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	manifest, err := capture(context.Background(), client, dynamicClient, Options{
+		Namespace: "default",
+		Analyzers: []string{"Pod", "Ingress", "Log", "Unknown"},
+		TailLines: 10,
+	}, f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	require.Equal(t, FormatVersion, manifest.FormatVersion)
+	require.Len(t, manifest.Logs, 1)
+	require.Contains(t, manifest.Warnings, "analyzer Unknown does not declare the resources it reads; its inputs are not captured")
+
+	counts := map[string]int{}
+	for _, r := range manifest.Resources {
+		counts[r.Resource] = r.Count
+	}
+	require.Equal(t, map[string]int{"pods": 1, "events": 0, "secrets": 1}, counts)
+
+	restored, err := kubernetes.NewClientFromSnapshot(path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	pods, err := restored.GetClient().CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, pods.Items, 1)
+
+	secret, err := restored.GetClient().CoreV1().Secrets("default").Get(ctx, "tls", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Empty(t, secret.Data["tls.key"])
+
+	logs, err := restored.GetClient().CoreV1().Pods("default").GetLogs("example", &v1.PodLogOptions{Container: "app"}).DoRaw(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "fake logs", string(logs))
+}
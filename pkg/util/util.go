@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -211,6 +212,27 @@ func EnsureDirExists(dir string) error {
 	return err
 }
 
+// WriteFileAtomic writes data to a temporary file renamed to path, so that an
+// interrupted write leaves the previous content of path intact.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
 func MapToString(m map[string]string) string {
 	// Handle empty map case
 	if len(m) == 0 {